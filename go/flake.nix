@@ -77,6 +77,13 @@
             srcBackend = ./services/notification/backend;
             port = "8085";
           };
+
+          greeter = buildService {
+            inherit pkgs gomod2nix;
+            name = "greeter";
+            srcBackend = ./services/greeter/backend;
+            port = "8086";
+          };
         };
 
         # Пакеты для локальной разработки (default)
@@ -132,6 +139,11 @@
                 servicePkg = k8sPackages.notification;
                 name = "notification";
             };
+            greeter = buildImage {
+                inherit pkgs;
+                servicePkg = k8sPackages.greeter;
+                name = "greeter";
+            };
         };
 
       in
@@ -151,6 +163,7 @@
               localPackages.landing
               localPackages.chat
               localPackages.notification
+              localPackages.greeter
             ];
           };
 
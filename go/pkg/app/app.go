@@ -0,0 +1,191 @@
+// Package app - общий жизненный цикл сервиса: компоненты (HTTP/gRPC-серверы, консьюмеры, телеметрия)
+// регистрируются в Runner в порядке зависимостей, запускаются по порядку и останавливаются в обратном
+// порядке с общим дедлайном. Первая ошибка компонента останавливает сервис с ненулевым кодом выхода.
+// Ошибки инициализации в main после app.New передаются в Runner.Fail, а не в os.Exit.
+//
+//	runner := app.New(cfg.Server.ShutdownTimeout)
+//	runner.Add(app.Component{Name: "telemetry", Stop: shutdownTelemetry}) // останавливается последним
+//	runner.Add(app.HTTP(":"+cfg.Server.HTTPPort, httpServer))
+//	runner.Main()
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Component - часть сервиса со своим жизненным циклом; любой хук можно не задавать.
+type Component struct {
+	Name string
+	// Start - быстрый запуск (net.Listen, подключение); ошибка прерывает запуск сервиса
+	Start func(ctx context.Context) error
+	// Run - блокирующая работа (Serve, цикл консьюмера) в отдельной горутине. ctx отменяется перед Stop
+	// этого компонента; ошибка до остановки останавливает сервис.
+	Run func(ctx context.Context) error
+	// Stop - остановка; ctx истекает через shutdown timeout, общий для всех компонентов
+	Stop func(ctx context.Context) error
+}
+
+// Runner запускает и останавливает компоненты
+type Runner struct {
+	timeout    time.Duration
+	components []Component
+}
+
+// New создает Runner; timeout ограничивает остановку всех компонентов вместе (<= 0 - 15 секунд)
+func New(timeout time.Duration) *Runner {
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+	return &Runner{timeout: timeout}
+}
+
+// Add регистрирует компонент; запуск - в порядке добавления, остановка - в обратном
+func (r *Runner) Add(c Component) {
+	r.components = append(r.components, c)
+}
+
+// running - запущенный компонент
+type running struct {
+	Component
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Run запускает компоненты и ждет отмены ctx или первой ошибки Run, затем останавливает запущенные.
+// Возвращает ошибку запуска или работы вместе с ошибками остановки; nil - штатная остановка.
+func (r *Runner) Run(ctx context.Context) error {
+	log := slog.Default()
+	failed := make(chan error, len(r.components))
+
+	var (
+		started []*running
+		err     error
+	)
+	for _, c := range r.components {
+		if c.Start != nil {
+			if startErr := c.Start(ctx); startErr != nil {
+				err = fmt.Errorf("failed to start %s: %w", c.Name, startErr)
+				break
+			}
+		}
+
+		// Run не должен прерываться вместе с ctx: компоненты останавливаются по очереди
+		runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		rc := &running{Component: c, cancel: cancel, done: make(chan struct{})}
+		if c.Run != nil {
+			go func() {
+				defer close(rc.done)
+				if runErr := c.Run(runCtx); runErr != nil && runCtx.Err() == nil {
+					failed <- fmt.Errorf("%s failed: %w", c.Name, runErr)
+				}
+			}()
+		} else {
+			close(rc.done)
+		}
+		started = append(started, rc)
+	}
+
+	if err == nil {
+		log.Info("✅ Service started", "components", len(started))
+		select {
+		case <-ctx.Done():
+			log.Info("🛑 Shutdown signal received")
+		case err = <-failed:
+		}
+	}
+	if err != nil {
+		log.Error("❌ Service failed, shutting down", "error", err)
+	}
+
+	return errors.Join(err, r.stop(ctx, started))
+}
+
+// stop останавливает компоненты в обратном порядке, пока не истечет общий дедлайн
+func (r *Runner) stop(ctx context.Context, started []*running) error {
+	log := slog.Default()
+	stopCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), r.timeout)
+	defer cancel()
+
+	var errs []error
+	for i := len(started) - 1; i >= 0; i-- {
+		c := started[i]
+		c.cancel()
+		var stopErr error
+		if c.Stop != nil {
+			stopErr = c.Stop(stopCtx)
+		}
+		switch {
+		case stopErr != nil:
+			errs = append(errs, fmt.Errorf("failed to stop %s: %w", c.Name, stopErr))
+		case !waitDone(stopCtx, c.done):
+			errs = append(errs, fmt.Errorf("%s did not stop: %w", c.Name, stopCtx.Err()))
+		}
+	}
+
+	if len(errs) > 0 {
+		log.Error("❌ Shutdown finished with errors", "error", errors.Join(errs...))
+	} else {
+		log.Info("👋 Shutdown complete")
+	}
+	return errors.Join(errs...)
+}
+
+// waitDone ждет завершения Run; false - дедлайн истек раньше
+func waitDone(ctx context.Context, done <-chan struct{}) bool {
+	select {
+	case <-done:
+		return true
+	default:
+	}
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Fail - ошибка инициализации в main после регистрации первых компонентов (нет брокеров, битый flags.yaml):
+// они останавливаются в обратном порядке, как при ошибке Run (телеметрия отправляет накопленное),
+// затем процесс завершается с кодом 1. os.Exit напрямую пропустил бы остановку.
+func (r *Runner) Fail(err error) {
+	_ = r.abort(context.Background(), err)
+	os.Exit(1)
+}
+
+// abort останавливает компоненты, которые еще не запускались. Stop вызывается только у компонентов без
+// Start и Run: их ресурсы созданы при регистрации (экспортеры телеметрии, продьюсер Kafka); серверам
+// и консьюмерам до запуска освобождать нечего.
+func (r *Runner) abort(ctx context.Context, err error) error {
+	slog.Default().Error("❌ Service failed to start, shutting down", "error", err)
+
+	var registered []*running
+	for _, c := range r.components {
+		if c.Start != nil || c.Run != nil {
+			continue
+		}
+		done := make(chan struct{})
+		close(done)
+		registered = append(registered, &running{Component: c, cancel: func() {}, done: done})
+	}
+	return errors.Join(err, r.stop(ctx, registered))
+}
+
+// Main запускает Run до SIGINT/SIGTERM и завершает процесс с кодом 1 при ошибке.
+// Отложенные (defer) вызовы main при этом не выполняются - все, что нужно закрыть, регистрируется компонентом.
+func (r *Runner) Main() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := r.Run(ctx); err != nil {
+		stop()
+		os.Exit(1)
+	}
+}
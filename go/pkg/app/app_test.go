@@ -0,0 +1,166 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// trace - журнал вызовов хуков компонентов
+type trace struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (tr *trace) add(call string) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.calls = append(tr.calls, call)
+}
+
+func (tr *trace) String() string {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	return strings.Join(tr.calls, ",")
+}
+
+// component - компонент, который пишет Start/Stop в журнал; Run блокируется до отмены ctx
+func (tr *trace) component(name string) Component {
+	return Component{
+		Name:  name,
+		Start: func(context.Context) error { tr.add("start " + name); return nil },
+		Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Stop: func(context.Context) error { tr.add("stop " + name); return nil },
+	}
+}
+
+func TestRunnerStopsInReverseOrder(t *testing.T) {
+	tr := &trace{}
+	r := New(time.Second)
+	r.Add(Component{Name: "telemetry", Stop: func(context.Context) error { tr.add("stop telemetry"); return nil }})
+	r.Add(tr.component("http"))
+	r.Add(tr.component("health"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := r.Run(ctx); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if got, want := tr.String(), "start http,start health,stop health,stop http,stop telemetry"; got != want {
+		t.Errorf("calls = %s, want %s", got, want)
+	}
+}
+
+func TestRunnerRunFailureStopsService(t *testing.T) {
+	tr := &trace{}
+	boom := errors.New("broker is gone")
+
+	r := New(time.Second)
+	r.Add(tr.component("producer"))
+	r.Add(Component{
+		Name: "consumer",
+		Run:  func(context.Context) error { return boom },
+		Stop: func(context.Context) error { tr.add("stop consumer"); return nil },
+	})
+
+	err := r.Run(context.Background())
+	if !errors.Is(err, boom) {
+		t.Fatalf("error = %v, want %v", err, boom)
+	}
+	if !strings.Contains(err.Error(), "consumer failed") {
+		t.Errorf("error = %q, want component name", err)
+	}
+	if got, want := tr.String(), "start producer,stop consumer,stop producer"; got != want {
+		t.Errorf("calls = %s, want %s", got, want)
+	}
+}
+
+func TestRunnerStartFailureStopsStarted(t *testing.T) {
+	tr := &trace{}
+	r := New(time.Second)
+	r.Add(tr.component("http"))
+	r.Add(Component{
+		Name:  "grpc",
+		Start: func(context.Context) error { return errors.New("address already in use") },
+		Stop:  func(context.Context) error { tr.add("stop grpc"); return nil },
+	})
+	r.Add(tr.component("health"))
+
+	err := r.Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "failed to start grpc: address already in use") {
+		t.Fatalf("error = %v, want start failure", err)
+	}
+	if got, want := tr.String(), "start http,stop http"; got != want {
+		t.Errorf("calls = %s, want %s", got, want)
+	}
+}
+
+func TestRunnerStopTimeout(t *testing.T) {
+	tr := &trace{}
+	release := make(chan struct{})
+	defer close(release)
+
+	r := New(50 * time.Millisecond)
+	r.Add(Component{Name: "telemetry", Stop: func(context.Context) error { tr.add("stop telemetry"); return nil }})
+	r.Add(Component{
+		Name: "stuck",
+		// Run не реагирует на отмену ctx
+		Run: func(context.Context) error {
+			<-release
+			return nil
+		},
+	})
+	r.Add(Component{
+		Name: "slow",
+		Stop: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	err := r.Run(ctx)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("stop took %v, want bounded by the shutdown timeout", elapsed)
+	}
+	if err == nil {
+		t.Fatal("expected stop errors")
+	}
+	for _, want := range []string{"failed to stop slow", "stuck did not stop"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %q, want containing %q", err, want)
+		}
+	}
+	// Компоненты после истекшего дедлайна все равно получают Stop
+	if got := tr.String(); got != "stop telemetry" {
+		t.Errorf("calls = %s, want stop telemetry", got)
+	}
+}
+
+func TestRunnerAbort(t *testing.T) {
+	tr := &trace{}
+	boom := errors.New("no brokers")
+
+	r := New(time.Second)
+	r.Add(Component{Name: "telemetry", Stop: func(context.Context) error { tr.add("stop telemetry"); return nil }})
+	r.Add(Component{Name: "producer", Stop: func(context.Context) error { tr.add("stop producer"); return nil }})
+	r.Add(tr.component("http"))
+
+	err := r.abort(context.Background(), boom)
+	if !errors.Is(err, boom) {
+		t.Fatalf("error = %v, want %v", err, boom)
+	}
+	if got, want := tr.String(), "stop producer,stop telemetry"; got != want {
+		t.Errorf("calls = %s, want %s", got, want)
+	}
+}
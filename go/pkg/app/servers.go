@@ -0,0 +1,76 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+
+	"google.golang.org/grpc"
+)
+
+// HTTPServer - то, что нужно от *http.Server (и оберток сервисов над ним)
+type HTTPServer interface {
+	Serve(lis net.Listener) error
+	Shutdown(ctx context.Context) error
+}
+
+// HTTP - компонент HTTP-сервера: порт занимается в Start (ошибка bind видна сразу), Stop ждет
+// завершения активных запросов до дедлайна остановки.
+func HTTP(addr string, srv HTTPServer) Component {
+	var lis net.Listener
+	return Component{
+		Name: "http " + addr,
+		Start: func(context.Context) error {
+			var err error
+			if lis, err = net.Listen("tcp", addr); err != nil {
+				return fmt.Errorf("failed to listen: %w", err)
+			}
+			slog.Default().Info("🚀 HTTP server listening", "addr", lis.Addr().String())
+			return nil
+		},
+		Run: func(context.Context) error {
+			if err := srv.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+		Stop: srv.Shutdown,
+	}
+}
+
+// GRPC - компонент gRPC-сервера. Stop - GracefulStop; если активные вызовы не завершились до дедлайна,
+// соединения закрываются принудительно.
+func GRPC(addr string, srv *grpc.Server) Component {
+	var lis net.Listener
+	return Component{
+		Name: "grpc " + addr,
+		Start: func(context.Context) error {
+			var err error
+			if lis, err = net.Listen("tcp", addr); err != nil {
+				return fmt.Errorf("failed to listen: %w", err)
+			}
+			slog.Default().Info("🚀 gRPC server listening", "addr", lis.Addr().String())
+			return nil
+		},
+		Run: func(context.Context) error {
+			return srv.Serve(lis)
+		},
+		Stop: func(ctx context.Context) error {
+			done := make(chan struct{})
+			go func() {
+				srv.GracefulStop()
+				close(done)
+			}()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				srv.Stop()
+				return fmt.Errorf("graceful stop interrupted: %w", ctx.Err())
+			}
+		},
+	}
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"text/tabwriter"
+)
+
+// Виды источников значения ключа (в порядке возрастания приоритета)
+const (
+	SourceUnset      = "unset"       // значение не задано и у поля нет default
+	SourceDefault    = "default"     // тег default
+	SourceFile       = "file"        // слой файлов конфигурации (configs/<env>.env и т.д.)
+	SourceEnv        = "env"         // переменная окружения
+	SourceSecretFile = "secret_file" // файл из <ENV>_FILE
+)
+
+// Source - откуда взято значение ключа конфигурации
+type Source struct {
+	Kind string `json:"kind"`
+	// Origin - путь к файлу слоя, имя переменной окружения или <ENV>_FILE=<путь>
+	Origin string `json:"origin,omitempty"`
+}
+
+func (s Source) String() string {
+	if s.Origin == "" {
+		return s.Kind
+	}
+	return s.Kind + " " + s.Origin
+}
+
+// KeyExplanation - итоговое значение ключа и его источник
+type KeyExplanation struct {
+	Key    string      `json:"key"`
+	Env    string      `json:"env"`
+	Value  interface{} `json:"value"`
+	Source Source      `json:"source"`
+}
+
+// Explain возвращает значения всех ключей cfg (секреты замаскированы) и их источники.
+// cfg должен быть результатом последнего Unmarshal этого загрузчика.
+func (l *Loader) Explain(cfg interface{}) []KeyExplanation {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.explain(cfg)
+}
+
+// explain вызывается под l.mu
+func (l *Loader) explain(cfg interface{}) []KeyExplanation {
+	var out []KeyExplanation
+	walkFields(reflect.ValueOf(cfg), nil, func(key string, field reflect.StructField, value reflect.Value) {
+		src, ok := l.sources[key]
+		if !ok {
+			src = Source{Kind: SourceUnset}
+		}
+		out = append(out, KeyExplanation{
+			Key:    key,
+			Env:    l.envKey(key),
+			Value:  redactedValue(field, value),
+			Source: src,
+		})
+	})
+	return out
+}
+
+// PrintConfig печатает итоговую конфигурацию таблицей: ключ, переменная, значение, источник.
+// Используется флагом --print-config.
+func (l *Loader) PrintConfig(w io.Writer, cfg interface{}) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tENV\tVALUE\tSOURCE")
+	for _, e := range l.Explain(cfg) {
+		fmt.Fprintf(tw, "%s\t%s\t%v\t%s\n", e.Key, e.Env, e.Value, e.Source)
+	}
+	return tw.Flush()
+}
+
+// Handler отдает в JSON текущую конфигурацию (с учетом hot reload) и источники значений.
+// Секреты замаскированы, но эндпоинт все равно не стоит выставлять наружу через gateway.
+func (l *Loader) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		l.mu.Lock()
+		if l.current == nil {
+			l.mu.Unlock()
+			http.Error(w, "config is not loaded", http.StatusServiceUnavailable)
+			return
+		}
+		body := map[string]interface{}{
+			"env":   l.env,
+			"files": l.filePaths(),
+			"keys":  l.explain(l.current),
+		}
+		l.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(body)
+	})
+}
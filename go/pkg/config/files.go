@@ -0,0 +1,269 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// configExts - поддерживаемые форматы файлов конфигурации.
+//   - env: плоские ключи с префиксом сервиса (CHAT_KAFKA_BROKERS=a:9092,b:9092)
+//   - yaml/yml/toml: вложенные ключи без префикса (kafka.brokers) - общие для всех сервисов,
+//     и секция с именем сервиса (chat.kafka.brokers), которая их перекрывает
+var configExts = []string{"env", "yaml", "yml", "toml"}
+
+// Имена слоев, не зависящие от окружения и сервиса
+const (
+	baseLayer  = "base"
+	localLayer = "local"
+)
+
+// configFile - прочитанный слой конфигурации
+type configFile struct {
+	path   string
+	format string
+	v      *viper.Viper      // yaml/toml
+	env    map[string]string // env: значения после подстановки ${VAR}, ключи в верхнем регистре
+}
+
+// layerNames возвращает имена слоев в порядке возрастания приоритета:
+// base -> <APP_ENV> -> <сервис> -> local.
+// Совпадающие имена (APP_ENV=local) схлопываются в последний слой.
+func (l *Loader) layerNames() []string {
+	names := []string{baseLayer, l.env}
+	if l.prefix != "" {
+		names = append(names, strings.ToLower(l.prefix))
+	}
+	names = append(names, localLayer)
+
+	var out []string
+	for i, name := range names {
+		duplicate := false
+		for _, later := range names[i+1:] {
+			if later == name {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// isLayerFile проверяет, является ли имя файла (без директории) одним из слоев загрузчика
+func (l *Loader) isLayerFile(name string) bool {
+	for _, layer := range l.layerNames() {
+		for _, ext := range configExts {
+			if name == layer+"."+ext {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// readFiles находит и читает слои в l.dir. Отсутствующий слой пропускается,
+// два файла одного слоя в разных форматах (dev.env и dev.yaml) - ошибка.
+// В env-слоях подставляются ${VAR}: ссылаться можно и на ключи нижних env-слоев (см. envResolver).
+func (l *Loader) readFiles() ([]*configFile, error) {
+	var files []*configFile
+	lowerEnv := make(map[string]string)
+
+	for _, layer := range l.layerNames() {
+		var found []*configFile
+		for _, ext := range configExts {
+			path := filepath.Join(l.dir, layer+"."+ext)
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+
+			if ext == "env" {
+				env, err := readEnvFile(path, lowerEnv)
+				if err != nil {
+					return nil, err
+				}
+				found = append(found, &configFile{path: path, format: ext, env: env})
+				continue
+			}
+
+			v := viper.New()
+			v.SetConfigFile(path)
+			if err := v.ReadInConfig(); err != nil {
+				return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+			}
+			found = append(found, &configFile{path: path, format: ext, v: v})
+		}
+
+		if len(found) > 1 {
+			paths := make([]string, len(found))
+			for i, f := range found {
+				paths[i] = f.path
+			}
+			return nil, fmt.Errorf("config layer %q is defined more than once: %s", layer, strings.Join(paths, ", "))
+		}
+		files = append(files, found...)
+		for _, f := range found {
+			for key, value := range f.env {
+				lowerEnv[key] = value
+			}
+		}
+	}
+	return files, nil
+}
+
+// readEnvFile читает env-файл и подставляет переменные.
+// Viper (gotenv) молча заменяет неизвестные ${VAR} пустой строкой, поэтому файл разбирается здесь.
+func readEnvFile(path string, lower map[string]string) (map[string]string, error) {
+	entries, order, err := parseEnvFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	values, err := interpolateEnvFile(path, entries, order, lower)
+	if err != nil {
+		return nil, fmt.Errorf("failed to interpolate config file: %w", err)
+	}
+
+	env := make(map[string]string, len(values))
+	for key, value := range values {
+		env[strings.ToUpper(key)] = value
+	}
+	return env, nil
+}
+
+// lookup ищет значение ключа в файле.
+// envName - имя переменной для env-формата (CHAT_KAFKA_BROKERS), key - вложенный ключ (kafka.brokers).
+func (f *configFile) lookup(service, envName, key string) (interface{}, bool) {
+	if f.format == "env" {
+		value, ok := f.env[strings.ToUpper(envName)]
+		return value, ok
+	}
+
+	if service != "" {
+		if serviceKey := strings.ToLower(service) + "." + key; f.v.IsSet(serviceKey) {
+			return f.v.Get(serviceKey), true
+		}
+	}
+	if f.v.IsSet(key) {
+		return f.v.Get(key), true
+	}
+	return nil, false
+}
+
+// fileValue возвращает значение ключа из слоя с наибольшим приоритетом и путь к этому файлу.
+// Значение слоя заменяет нижние целиком: слайсы (kafka.brokers) не склеиваются.
+// Вызывается под l.mu.
+func (l *Loader) fileValue(key string) (interface{}, string, bool) {
+	for i := len(l.files) - 1; i >= 0; i-- {
+		f := l.files[i]
+		if value, ok := f.lookup(l.prefix, l.envKey(key), key); ok {
+			return value, f.path, true
+		}
+	}
+	return nil, "", false
+}
+
+// fileSecretPath возвращает путь к файлу секрета, заданный в слоях:
+// CHAT_DATABASE_PASSWORD_FILE в env-формате или database.password_file в yaml/toml.
+// Вызывается под l.mu.
+func (l *Loader) fileSecretPath(key string) string {
+	for i := len(l.files) - 1; i >= 0; i-- {
+		f := l.files[i]
+		if value, ok := f.lookup(l.prefix, l.envKey(key)+secretFileSuffix, key+strings.ToLower(secretFileSuffix)); ok {
+			return fmt.Sprint(value)
+		}
+	}
+	return ""
+}
+
+// filePaths возвращает пути прочитанных слоев в порядке приоритета
+func (l *Loader) filePaths() []string {
+	paths := make([]string, len(l.files))
+	for i, f := range l.files {
+		paths[i] = f.path
+	}
+	return paths
+}
+
+// UnknownKeys возвращает ключи из слоев, которым не соответствует ни одно поле конфигурации
+// (опечатки вида CHAT_KAFKA_BROKER). Требует предварительного Unmarshal: список полей
+// собирается в bindEnvs.
+//
+// В env-файлах проверяются только ключи с префиксом этого сервиса и хотя бы двумя частями
+// после него (CHAT_<секция>_<поле>): общие env-файлы содержат переменные других сервисов
+// и инфраструктуры (CHAT_HOST для gateway, KAFKA_PORT для docker-compose).
+// В yaml/toml проверяются все ключи, включая секции других сервисов.
+func (l *Loader) UnknownKeys() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.unknownKeys()
+}
+
+// unknownKeys вызывается под l.mu
+func (l *Loader) unknownKeys() []string {
+	known := make(map[string]bool)
+	for _, key := range l.keys {
+		known[key] = true
+		known[key+strings.ToLower(secretFileSuffix)] = true
+	}
+
+	sections := make(map[string]bool)
+	for _, prefix := range ServicePrefixes {
+		sections[strings.ToLower(prefix)] = true
+	}
+	if l.prefix != "" {
+		sections[strings.ToLower(l.prefix)] = true
+	}
+
+	ownPrefix := strings.ToUpper(l.prefix) + "_"
+	var unknown []string
+	for _, f := range l.files {
+		var fileKeys []string
+		if f.format == "env" {
+			for key := range f.env {
+				fileKeys = append(fileKeys, key)
+			}
+		} else {
+			fileKeys = f.v.AllKeys()
+		}
+		sort.Strings(fileKeys)
+
+		for _, fileKey := range fileKeys {
+			var ok bool
+			if f.format == "env" {
+				rest, hasPrefix := strings.CutPrefix(fileKey, ownPrefix)
+				if l.prefix == "" || !hasPrefix || !strings.Contains(rest, "_") {
+					continue
+				}
+				ok = l.knownEnvKey(fileKey)
+			} else {
+				key := fileKey
+				if section, rest, found := strings.Cut(fileKey, "."); found && sections[section] {
+					key = rest
+				}
+				ok = known[key]
+			}
+
+			if !ok {
+				unknown = append(unknown, fmt.Sprintf("%s (%s)", fileKey, f.path))
+			}
+		}
+	}
+	return unknown
+}
+
+// knownEnvKey проверяет, соответствует ли переменная полю: CHAT_KAFKA_BROKERS или CHAT_KAFKA_BROKERS_FILE.
+// Сравнение идет по envKey, а не разбором имени: подчеркивания есть и внутри имен полей (http_port).
+func (l *Loader) knownEnvKey(name string) bool {
+	for _, key := range l.keys {
+		if env := l.envKey(key); name == env || name == env+secretFileSuffix {
+			return true
+		}
+	}
+	return false
+}
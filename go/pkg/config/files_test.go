@@ -0,0 +1,199 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeLayers создает файлы слоев во временной директории и направляет на нее Loader
+func writeLayers(t *testing.T, env string, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("CONFIG_PATH", dir)
+	t.Setenv("APP_ENV", env)
+	return dir
+}
+
+func TestLoadLayerOrder(t *testing.T) {
+	dir := writeLayers(t, "dev", map[string]string{
+		"base.yaml":    "server:\n  host: base-host\n  http_port: 1000\n  timeout: 1s\nkafka:\n  brokers: [base:9092, base2:9092]\n",
+		"dev.env":      "TESTSVC_SERVER_HTTP_PORT=2000\nTESTSVC_SERVER_TIMEOUT=2s\nTESTSVC_KAFKA_BROKERS=dev:9092\n",
+		"testsvc.toml": "[server]\nhttp_port = 3000\n\n[testsvc.server]\ntimeout = \"3s\"\n",
+		"local.yaml":   "server:\n  http_port: 4000\n",
+	})
+
+	l := NewLoader("testsvc")
+	if err := l.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	var cfg testConfig
+	if err := l.Unmarshal(&cfg); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if cfg.Server.Host != "base-host" {
+		t.Errorf("host = %q, want base-host", cfg.Server.Host)
+	}
+	if cfg.Server.HTTPPort != 4000 {
+		t.Errorf("http_port = %d, want 4000 from local", cfg.Server.HTTPPort)
+	}
+	if cfg.Server.Timeout.String() != "3s" {
+		t.Errorf("timeout = %v, want 3s from service section", cfg.Server.Timeout)
+	}
+	// Слайс верхнего слоя заменяет нижний целиком
+	if got := cfg.Kafka.Brokers; len(got) != 1 || got[0] != "dev:9092" {
+		t.Errorf("brokers = %v, want [dev:9092]", got)
+	}
+
+	wantSources := map[string]string{
+		"server.host":      "base.yaml",
+		"server.http_port": "local.yaml",
+		"server.timeout":   "testsvc.toml",
+		"kafka.brokers":    "dev.env",
+	}
+	for key, file := range wantSources {
+		got := l.sources[key]
+		if got.Kind != SourceFile || got.Origin != filepath.Join(dir, file) {
+			t.Errorf("%s source = %+v, want file %s", key, got, file)
+		}
+	}
+
+	want := []string{"base.yaml", "dev.env", "testsvc.toml", "local.yaml"}
+	paths := l.filePaths()
+	if len(paths) != len(want) {
+		t.Fatalf("files = %v, want %v", paths, want)
+	}
+	for i, w := range want {
+		if filepath.Base(paths[i]) != w {
+			t.Errorf("files[%d] = %s, want %s", i, paths[i], w)
+		}
+	}
+}
+
+func TestLoadEnvOverridesLayers(t *testing.T) {
+	writeLayers(t, "dev", map[string]string{
+		"local.yaml": "server:\n  host: local-host\n",
+	})
+	t.Setenv("TESTSVC_SERVER_HOST", "env-host")
+
+	l := NewLoader("testsvc")
+	if err := l.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	var cfg testConfig
+	if err := l.Unmarshal(&cfg); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if cfg.Server.Host != "env-host" {
+		t.Errorf("host = %q, want env-host", cfg.Server.Host)
+	}
+	if got := l.sources["server.host"]; got.Kind != SourceEnv {
+		t.Errorf("server.host source = %+v, want env", got)
+	}
+}
+
+func TestLoadMissingLayers(t *testing.T) {
+	writeLayers(t, "prod", map[string]string{
+		"base.env": "TESTSVC_SERVER_HOST=base-host\n",
+		// Файлы чужого окружения не читаются
+		"dev.env": "TESTSVC_SERVER_HOST=dev-host\n",
+	})
+
+	l := NewLoader("testsvc")
+	if err := l.Load(); err != nil {
+		t.Fatalf("Load with missing layers: %v", err)
+	}
+	var cfg testConfig
+	if err := l.Unmarshal(&cfg); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if cfg.Server.Host != "base-host" {
+		t.Errorf("host = %q, want base-host", cfg.Server.Host)
+	}
+	if cfg.Server.HTTPPort != 8080 {
+		t.Errorf("http_port = %d, want default 8080", cfg.Server.HTTPPort)
+	}
+}
+
+func TestLoadEmptyDir(t *testing.T) {
+	writeLayers(t, "dev", nil)
+
+	l := NewLoader("testsvc")
+	if err := l.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if paths := l.filePaths(); len(paths) != 0 {
+		t.Errorf("files = %v, want none", paths)
+	}
+}
+
+func TestLoadDuplicateLayer(t *testing.T) {
+	writeLayers(t, "dev", map[string]string{
+		"dev.env":  "TESTSVC_SERVER_HOST=a\n",
+		"dev.yaml": "server:\n  host: b\n",
+	})
+
+	err := NewLoader("testsvc").Load()
+	if err == nil || !strings.Contains(err.Error(), `config layer "dev" is defined more than once`) {
+		t.Fatalf("error = %v, want duplicate layer error", err)
+	}
+}
+
+func TestLayerNamesCollapseDuplicates(t *testing.T) {
+	t.Setenv("APP_ENV", "local")
+	got := strings.Join(NewLoader("testsvc").layerNames(), ",")
+	if want := "base,testsvc,local"; got != want {
+		t.Errorf("layers = %s, want %s", got, want)
+	}
+}
+
+func TestUnknownKeys(t *testing.T) {
+	writeLayers(t, "dev", map[string]string{
+		"dev.env": strings.Join([]string{
+			"KAFKA_PORT=9092",              // инфраструктура - не проверяется
+			"CHAT_HOST=chat",               // одна часть после префикса (gateway) - не проверяется
+			"NOTIFICATION_KAFKA_GROUPID=x", // чужой сервис - не проверяется
+			"CHAT_SERVICES_NOTIFICATION_ENDPOINT=notification:50051", // подчеркивания внутри имени поля
+			"CHAT_KAFKA_BROKERS=localhost:${KAFKA_PORT}",
+			"CHAT_KAFKA_TOPIC=chat-messages",
+			"CHAT_KAFKA_BROKER=localhost:9092", // опечатка
+			"CHAT_KAFKA_GROUPID=chat-group",    // опечатка: group_id
+			"CHAT_DATABASE_PASSWORD_FILE=/run/secrets/db",
+		}, "\n") + "\n",
+		"chat.yaml": "kafka:\n  topic: shared\n  topik: typo\nnotification:\n  kafka:\n    group_id: notification-group\nchat:\n  services:\n    notification_endpointt: typo\n",
+	})
+	secret := filepath.Join(t.TempDir(), "db")
+	if err := os.WriteFile(secret, []byte("pw\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CHAT_DATABASE_PASSWORD_FILE", secret)
+
+	l := NewLoader("CHAT")
+	if err := l.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	var cfg AppConfig
+	if err := l.Unmarshal(&cfg); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if cfg.Services.NotificationEndpoint != "notification:50051" {
+		t.Errorf("notification_endpoint = %q", cfg.Services.NotificationEndpoint)
+	}
+
+	var got []string
+	for _, key := range l.UnknownKeys() {
+		name, _, _ := strings.Cut(key, " ")
+		got = append(got, name)
+	}
+	want := "CHAT_KAFKA_BROKER,CHAT_KAFKA_GROUPID,chat.services.notification_endpointt,kafka.topik"
+	if strings.Join(got, ",") != want {
+		t.Errorf("unknown keys = %v, want %s", got, want)
+	}
+}
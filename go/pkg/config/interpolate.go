@@ -0,0 +1,245 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// envEntry - сырое значение из env-файла до подстановки переменных
+type envEntry struct {
+	value  string
+	line   int
+	expand bool // false для значений в одинарных кавычках
+}
+
+// parseEnvFile читает env-файл без подстановки переменных.
+// Поддерживается: KEY=VALUE, export KEY=VALUE, комментарии (# в начале строки
+// или после пробела в значении без кавычек), значения в "..." (с \n, \", \\) и '...' (как есть).
+func parseEnvFile(path string) (map[string]envEntry, []string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	entries := make(map[string]envEntry)
+	var order []string
+
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, raw, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, lineNo)
+		}
+
+		entry := envEntry{line: lineNo, expand: true}
+		raw = strings.TrimSpace(raw)
+		switch {
+		case strings.HasPrefix(raw, `'`):
+			end := strings.Index(raw[1:], `'`)
+			if end < 0 {
+				return nil, nil, fmt.Errorf("%s:%d: unterminated single quote", path, lineNo)
+			}
+			entry.value = raw[1 : end+1]
+			entry.expand = false
+		case strings.HasPrefix(raw, `"`):
+			value, err := unquoteDouble(raw)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s:%d: %w", path, lineNo, err)
+			}
+			entry.value = value
+		default:
+			if i := strings.Index(raw, " #"); i >= 0 {
+				raw = raw[:i]
+			}
+			if i := strings.Index(raw, "\t#"); i >= 0 {
+				raw = raw[:i]
+			}
+			entry.value = strings.TrimSpace(raw)
+		}
+
+		if _, exists := entries[key]; !exists {
+			order = append(order, key)
+		}
+		entries[key] = entry
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return entries, order, nil
+}
+
+// unquoteDouble разбирает значение в двойных кавычках; все после закрывающей кавычки игнорируется
+func unquoteDouble(raw string) (string, error) {
+	var b strings.Builder
+	for i := 1; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case c == '"':
+			return b.String(), nil
+		case c == '\\' && i+1 < len(raw):
+			i++
+			switch raw[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(raw[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", fmt.Errorf("unterminated double quote")
+}
+
+// envResolver подставляет ${VAR} и ${VAR:-default} в значения env-файла.
+//
+// Порядок поиска VAR: окружение процесса -> ключи этого же файла (в любом порядке) -> нижние слои.
+// Неопределенная переменная без default и циклические ссылки (A=${B}, B=${A}) - ошибка загрузки.
+// $$ дает символ $, одиночный $ без { остается как есть.
+type envResolver struct {
+	path     string
+	entries  map[string]envEntry
+	lower    map[string]string // уже вычисленные значения нижних слоев
+	resolved map[string]string
+	visiting map[string]bool
+	stack    []string
+}
+
+// interpolateEnvFile вычисляет все значения файла. lower - значения нижних env-слоев.
+func interpolateEnvFile(path string, entries map[string]envEntry, order []string, lower map[string]string) (map[string]string, error) {
+	r := &envResolver{
+		path:     path,
+		entries:  entries,
+		lower:    lower,
+		resolved: make(map[string]string),
+		visiting: make(map[string]bool),
+	}
+	for _, key := range order {
+		if _, err := r.resolve(key); err != nil {
+			return nil, err
+		}
+	}
+	return r.resolved, nil
+}
+
+func (r *envResolver) resolve(key string) (string, error) {
+	if value, ok := r.resolved[key]; ok {
+		return value, nil
+	}
+
+	entry := r.entries[key]
+	if !entry.expand {
+		r.resolved[key] = entry.value
+		return entry.value, nil
+	}
+
+	if r.visiting[key] {
+		cycle := append(append([]string(nil), r.stack...), key)
+		return "", fmt.Errorf("%s:%d: cyclic reference: %s", r.path, entry.line, strings.Join(cycle, " -> "))
+	}
+	r.visiting[key] = true
+	r.stack = append(r.stack, key)
+	defer func() {
+		delete(r.visiting, key)
+		r.stack = r.stack[:len(r.stack)-1]
+	}()
+
+	value, err := r.expand(key, entry.value)
+	if err != nil {
+		return "", err
+	}
+	r.resolved[key] = value
+	return value, nil
+}
+
+// lookup ищет значение переменной, на которую ссылается key
+func (r *envResolver) lookup(name string) (string, bool, error) {
+	if value, ok := os.LookupEnv(name); ok {
+		return value, true, nil
+	}
+	if _, ok := r.entries[name]; ok {
+		value, err := r.resolve(name)
+		return value, true, err
+	}
+	if value, ok := r.lower[name]; ok {
+		return value, true, nil
+	}
+	return "", false, nil
+}
+
+func (r *envResolver) expand(key, s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 >= len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		switch s[i+1] {
+		case '$':
+			b.WriteByte('$')
+			i++
+			continue
+		case '{':
+		default:
+			b.WriteByte('$')
+			continue
+		}
+
+		end := matchingBrace(s, i+1)
+		if end < 0 {
+			return "", fmt.Errorf("%s:%d: %s: unterminated ${", r.path, r.entries[key].line, key)
+		}
+		expr := s[i+2 : end]
+		i = end
+
+		name, def, hasDefault := strings.Cut(expr, ":-")
+		value, ok, err := r.lookup(name)
+		if err != nil {
+			return "", err
+		}
+		if hasDefault && value == "" {
+			// default тоже может содержать ссылки: ${A:-${B}}
+			if value, err = r.expand(key, def); err != nil {
+				return "", err
+			}
+			ok = true
+		}
+		if !ok {
+			return "", fmt.Errorf("%s:%d: %s references undefined variable %s", r.path, r.entries[key].line, key, name)
+		}
+		b.WriteString(value)
+	}
+	return b.String(), nil
+}
+
+// matchingBrace возвращает индекс }, закрывающей { в позиции open, с учетом вложенных ${...}
+func matchingBrace(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
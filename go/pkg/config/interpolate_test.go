@@ -0,0 +1,130 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadEnvFileInterpolation(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		env     map[string]string // окружение процесса
+		lower   map[string]string // значения нижних env-слоев
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name: "default when unset",
+			file: "HOST=${KAFKA_HOST_TEST:-localhost}\n",
+			want: map[string]string{"HOST": "localhost"},
+		},
+		{
+			name: "default when empty",
+			file: "HOST=${KAFKA_HOST_TEST:-localhost}\n",
+			env:  map[string]string{"KAFKA_HOST_TEST": ""},
+			want: map[string]string{"HOST": "localhost"},
+		},
+		{
+			name: "env over file key",
+			file: "KAFKA_HOST_TEST=file-host\nBROKER=${KAFKA_HOST_TEST}:9092\n",
+			env:  map[string]string{"KAFKA_HOST_TEST": "env-host"},
+			want: map[string]string{"KAFKA_HOST_TEST": "file-host", "BROKER": "env-host:9092"},
+		},
+		{
+			name: "env over default",
+			file: "HOST=${KAFKA_HOST_TEST:-localhost}\n",
+			env:  map[string]string{"KAFKA_HOST_TEST": "kafka"},
+			want: map[string]string{"HOST": "kafka"},
+		},
+		{
+			name: "forward reference in same file",
+			file: "BROKER=${KAFKA_HOST_TEST}:${KAFKA_PORT_TEST}\nKAFKA_HOST_TEST=kafka\nKAFKA_PORT_TEST=9092\n",
+			want: map[string]string{"BROKER": "kafka:9092", "KAFKA_HOST_TEST": "kafka", "KAFKA_PORT_TEST": "9092"},
+		},
+		{
+			name:  "lookup into lower layer",
+			file:  "BROKER=${KAFKA_HOST_TEST}:9092\n",
+			lower: map[string]string{"KAFKA_HOST_TEST": "base-host"},
+			want:  map[string]string{"BROKER": "base-host:9092"},
+		},
+		{
+			name:  "same file over lower layer",
+			file:  "KAFKA_HOST_TEST=dev-host\nBROKER=${KAFKA_HOST_TEST}:9092\n",
+			lower: map[string]string{"KAFKA_HOST_TEST": "base-host"},
+			want:  map[string]string{"KAFKA_HOST_TEST": "dev-host", "BROKER": "dev-host:9092"},
+		},
+		{
+			name: "nested default",
+			file: "URL=${URL_TEST:-http://${HOST_TEST:-localhost}:8080}\n",
+			want: map[string]string{"URL": "http://localhost:8080"},
+		},
+		{
+			name: "dollar escapes and single quotes",
+			file: "PRICE=$$5 and $x\nRAW='${NOT_EXPANDED}'\n",
+			want: map[string]string{"PRICE": "$5 and $x", "RAW": "${NOT_EXPANDED}"},
+		},
+		{
+			name: "keys are upper-cased",
+			file: "export chat_server_host=0.0.0.0 # comment\n",
+			want: map[string]string{"CHAT_SERVER_HOST": "0.0.0.0"},
+		},
+		{
+			name:    "undefined variable",
+			file:    "\nBROKER=${UNDEFINED_TEST}:9092\n",
+			wantErr: ":2: BROKER references undefined variable UNDEFINED_TEST",
+		},
+		{
+			name:    "cycle",
+			file:    "A=${B}\nB=${C}\nC=${A}\n",
+			wantErr: "cyclic reference: A -> B -> C -> A",
+		},
+		{
+			name:    "self reference",
+			file:    "A=x${A}\n",
+			wantErr: "cyclic reference: A -> A",
+		},
+		{
+			name:    "unterminated brace",
+			file:    "A=${B\n",
+			wantErr: "A: unterminated ${",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			path := filepath.Join(t.TempDir(), "dev.env")
+			if err := os.WriteFile(path, []byte(tt.file), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			lower := tt.lower
+			if lower == nil {
+				lower = map[string]string{}
+			}
+
+			got, err := readEnvFile(path, lower)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readEnvFile: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			for k, w := range tt.want {
+				if got[k] != w {
+					t.Errorf("%s = %q, want %q", k, got[k], w)
+				}
+			}
+		})
+	}
+}
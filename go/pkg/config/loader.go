@@ -1,78 +1,228 @@
 package config
 
 import (
-	"fmt"
+	"errors"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/spf13/viper"
 )
 
 // Loader загружает конфигурацию с использованием Viper
 type Loader struct {
-	v   *viper.Viper
-	env string
-	prefix string
+	mu        sync.Mutex
+	v         *viper.Viper
+	validator *Validator
+	env       string
+	prefix    string
+
+	// dir - директория с файлами конфигурации, files - прочитанные слои (см. files.go)
+	dir   string
+	files []*configFile
+
+	// keys - ключи конфигурации (server.http_port), найденные при обходе структуры в bindEnvs
+	keys []string
+
+	// hasSecretFiles - хотя бы одно значение прочитано из *_FILE (нужна периодическая перечитка)
+	hasSecretFiles bool
+
+	// sources - откуда взято значение каждого ключа при последнем принятом decode (см. explain.go)
+	sources map[string]Source
+
+	// Состояние для Watch (см. watch.go)
+	current *AppConfig
+	subs    []subscriber
+	nextSub int
+	stop    func()
 }
 
 // NewLoader создает новый загрузчик.
 func NewLoader(serviceName string) *Loader {
+	env := os.Getenv("APP_ENV")
+	if env == "" {
+		env = "dev"
+	}
+
+	l := &Loader{
+		validator: NewValidator(),
+		env:       env,
+		prefix:    serviceName,
+	}
+	l.v = l.newViper()
+	return l
+}
+
+// newViper создает Viper с привязкой к ENV по префиксу сервиса
+func (l *Loader) newViper() *viper.Viper {
 	v := viper.New()
 
 	v.AutomaticEnv()
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
-	if serviceName != "" {
-		v.SetEnvPrefix(serviceName)
+	if l.prefix != "" {
+		v.SetEnvPrefix(l.prefix)
 	}
+	return v
+}
 
-	env := os.Getenv("APP_ENV")
-	if env == "" {
-		env = "dev"
+// Load читает слои конфигурации из $CONFIG_PATH (или ближайшей директории configs/)
+// в порядке возрастания приоритета:
+//
+//  1. base.{env,yaml,yml,toml}    - общее для всех окружений и сервисов
+//  2. <APP_ENV>.{...}             - окружение (dev.env)
+//  3. <сервис>.{...}              - оверлей сервиса (chat.yaml)
+//  4. local.{...}                 - локальные правки разработчика, не коммитятся
+//
+// Любой слой может отсутствовать: тогда значения берутся из ENV и default.
+// Слои сливаются по ключам: значение верхнего слоя заменяет нижнее целиком,
+// слайсы (kafka.brokers) не склеиваются, а заменяются.
+// Пути прочитанных файлов пишутся в лог (slog, до настройки логгера - stderr): stdout остается чистым
+// для --print-config. Значения конфигурации Loader не выводит никогда.
+func (l *Loader) Load() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.dir = findConfigPath()
+
+	files, err := l.readFiles()
+	if err != nil {
+		return err
 	}
+	l.files = files
 
-	return &Loader{
-		v:      v,
-		env:    env,
-		prefix: serviceName,
+	for _, f := range files {
+		slog.Default().Info("✅ Loaded config", "path", f.path)
 	}
+
+	return nil
 }
 
-// Load загружает конфигурацию из файла (если есть)
-func (l *Loader) Load() error {
-	configPath := findConfigPath()
-	configName := l.env
+// Dir возвращает директорию с файлами конфигурации (известна после Load).
+// Рядом лежат и другие файлы сервисов, например configs/flags.yaml.
+func (l *Loader) Dir() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.dir
+}
 
-	l.v.SetConfigName(configName)
-	l.v.SetConfigType("env")
-	l.v.AddConfigPath(configPath)
+// Env возвращает окружение (APP_ENV, по умолчанию dev)
+func (l *Loader) Env() string {
+	return l.env
+}
 
-	// Пытаемся прочитать файл, но не умираем, если его нет (полагаемся на ENV)
-	if err := l.v.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return fmt.Errorf("failed to read config file: %w", err)
-		}
-		// Если файла нет, это нормально, идем дальше
-	} else {
-		fmt.Printf("✅ Loaded config from %s\n", l.v.ConfigFileUsed())
+// Unmarshal десериализует конфигурацию, применяет дефолты из тега default
+// и валидирует результат по тегу validate.
+// Ошибка валидации содержит сразу все невалидные поля (ValidationErrors).
+// Приоритет источников: <ENV>_FILE > переменная окружения > слои файлов (см. Load) > default.
+func (l *Loader) Unmarshal(cfg interface{}) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Источники запоминаем и при ошибке валидации: --print-config показывает, откуда пришло невалидное значение
+	sources := make(map[string]Source)
+	err := l.decode(cfg, sources)
+	l.sources = sources
+
+	// Опечатки в ключах не ломают загрузку, но значение молча не применяется - предупреждаем
+	for _, key := range l.unknownKeys() {
+		slog.Default().Warn("⚠️ Unknown config key: no matching config field", "key", key)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// Запоминаем снимок, относительно которого Watch будет считать изменения
+	if appCfg, ok := cfg.(*AppConfig); ok {
+		snapshot := *appCfg
+		l.current = &snapshot
 	}
 
 	return nil
 }
 
-// Unmarshal десериализует конфигурацию и биндит ENV переменные
-func (l *Loader) Unmarshal(cfg interface{}) error {
+// decode выполняет полный цикл: bind ENV -> значения из файла -> unmarshal -> *_FILE -> валидация.
+// В sources записывается источник значения каждого ключа. Вызывается под l.mu.
+func (l *Loader) decode(cfg interface{}, sources map[string]Source) error {
+	// Каждый раз собираем Viper заново: иначе значения, удаленные из файлов, остались бы после reload
+	l.v = l.newViper()
+
 	// ВАЖНО: Явно биндим ENV переменные для всех полей структуры
-	if err := l.bindEnvs(cfg); err != nil {
+	if err := l.bindEnvs(cfg, sources); err != nil {
 		return err
 	}
-	return l.v.Unmarshal(cfg)
+	if err := l.mergeFileValues(); err != nil {
+		return err
+	}
+	if err := l.v.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	errs := l.applySecretFiles(cfg, sources)
+
+	if err := l.validator.ValidateStruct(cfg); err != nil {
+		var verrs ValidationErrors
+		if !errors.As(err, &verrs) {
+			return err
+		}
+		for _, fe := range verrs {
+			fe.Env = l.envKey(fe.Key)
+			errs = append(errs, fe)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// mergeFileValues переносит значения из слоев файлов в ключи конфигурации.
+// env-файлы Viper читает как плоские ключи (chat_server_http_port), а структура ожидает
+// вложенные (server.http_port), поэтому для каждого ключа значение ищется по слоям (fileValue).
+func (l *Loader) mergeFileValues() error {
+	values := make(map[string]interface{})
+	for _, key := range l.keys {
+		value, _, ok := l.fileValue(key)
+		if !ok {
+			continue
+		}
+
+		parts := strings.Split(key, ".")
+		node := values
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := node[part].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				node[part] = child
+			}
+			node = child
+		}
+		node[parts[len(parts)-1]] = value
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+	return l.v.MergeConfigMap(values)
 }
 
-// bindEnvs рекурсивно проходит по полям структуры и делает v.BindEnv
-func (l *Loader) bindEnvs(iface interface{}, parts ...string) error {
+// envKey возвращает имя переменной окружения для ключа: server.http_port -> CHAT_SERVER_HTTP_PORT
+func (l *Loader) envKey(key string) string {
+	envKey := strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+	if l.prefix == "" {
+		return envKey
+	}
+	return strings.ToUpper(l.prefix) + "_" + envKey
+}
+
+// bindEnvs рекурсивно проходит по полям структуры, делает v.BindEnv,
+// регистрирует значения из тега default и записывает в sources источник значения ключа
+func (l *Loader) bindEnvs(iface interface{}, sources map[string]Source, parts ...string) error {
 	ifv := reflect.ValueOf(iface)
 	if ifv.Kind() == reflect.Ptr {
 		ifv = ifv.Elem()
@@ -88,7 +238,7 @@ func (l *Loader) bindEnvs(iface interface{}, parts ...string) error {
 
 		// Обрабатываем вложенные структуры (например, ServerConfig)
 		if field.Type.Kind() == reflect.Struct {
-			if err := l.bindEnvs(ifv.Field(i).Interface(), append(parts, tv)...); err != nil {
+			if err := l.bindEnvs(ifv.Field(i).Interface(), sources, append(parts, tv)...); err != nil {
 				return err
 			}
 			continue
@@ -99,14 +249,44 @@ func (l *Loader) bindEnvs(iface interface{}, parts ...string) error {
 		if err := l.v.BindEnv(key); err != nil {
 			return err
 		}
+		l.addKey(key)
+
+		def, hasDefault := field.Tag.Lookup("default")
+		if hasDefault {
+			l.v.SetDefault(key, def)
+		}
+		sources[key] = l.source(key, hasDefault)
 
 		// Debug log (optional, enabled for troubleshooting)
-		// envKey := strings.ToUpper(l.prefix + "_" + strings.ReplaceAll(key, ".", "_"))
-		// fmt.Printf("🔧 Binding Config Key '%s' -> Env '%s'\n", key, envKey)
+		// fmt.Printf("🔧 Binding Config Key '%s' -> Env '%s'\n", key, l.envKey(key))
 	}
 	return nil
 }
 
+// source определяет, откуда Viper возьмет значение ключа.
+// Viper считает пустую переменную окружения незаданной, здесь так же.
+func (l *Loader) source(key string, hasDefault bool) Source {
+	if env := l.envKey(key); os.Getenv(env) != "" {
+		return Source{Kind: SourceEnv, Origin: env}
+	}
+	if _, path, ok := l.fileValue(key); ok {
+		return Source{Kind: SourceFile, Origin: path}
+	}
+	if hasDefault {
+		return Source{Kind: SourceDefault}
+	}
+	return Source{Kind: SourceUnset}
+}
+
+func (l *Loader) addKey(key string) {
+	for _, k := range l.keys {
+		if k == key {
+			return
+		}
+	}
+	l.keys = append(l.keys, key)
+}
+
 func findConfigPath() string {
 	if path := os.Getenv("CONFIG_PATH"); path != "" {
 		return path
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	Server struct {
+		HTTPPort int           `mapstructure:"http_port" default:"8080" validate:"port"`
+		Timeout  time.Duration `mapstructure:"timeout" default:"5s"`
+		Host     string        `mapstructure:"host" validate:"required"`
+	} `mapstructure:"server"`
+	Kafka struct {
+		Brokers []string `mapstructure:"brokers" default:"localhost:9092" validate:"required,hostport"`
+	} `mapstructure:"kafka"`
+	Password string `mapstructure:"password" secret:"true"`
+}
+
+func TestUnmarshalDefaults(t *testing.T) {
+	t.Setenv("TESTSVC_SERVER_HOST", "0.0.0.0")
+
+	var cfg testConfig
+	if err := NewLoader("testsvc").Unmarshal(&cfg); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if cfg.Server.HTTPPort != 8080 {
+		t.Errorf("http_port = %d, want default 8080", cfg.Server.HTTPPort)
+	}
+	if cfg.Server.Timeout != 5*time.Second {
+		t.Errorf("timeout = %v, want default 5s", cfg.Server.Timeout)
+	}
+	if len(cfg.Kafka.Brokers) != 1 || cfg.Kafka.Brokers[0] != "localhost:9092" {
+		t.Errorf("brokers = %v, want default [localhost:9092]", cfg.Kafka.Brokers)
+	}
+}
+
+func TestUnmarshalEnvOverridesDefault(t *testing.T) {
+	t.Setenv("TESTSVC_SERVER_HOST", "0.0.0.0")
+	t.Setenv("TESTSVC_SERVER_HTTP_PORT", "9090")
+	t.Setenv("TESTSVC_KAFKA_BROKERS", "a:9092,b:9092")
+
+	l := NewLoader("testsvc")
+	var cfg testConfig
+	if err := l.Unmarshal(&cfg); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if cfg.Server.HTTPPort != 9090 {
+		t.Errorf("http_port = %d, want 9090", cfg.Server.HTTPPort)
+	}
+	if got := cfg.Kafka.Brokers; len(got) != 2 || got[0] != "a:9092" || got[1] != "b:9092" {
+		t.Errorf("brokers = %v, want [a:9092 b:9092]", got)
+	}
+
+	sources := l.sources
+	if got := sources["server.http_port"]; got.Kind != SourceEnv || got.Origin != "TESTSVC_SERVER_HTTP_PORT" {
+		t.Errorf("server.http_port source = %+v, want env TESTSVC_SERVER_HTTP_PORT", got)
+	}
+	if got := sources["server.timeout"]; got.Kind != SourceDefault {
+		t.Errorf("server.timeout source = %+v, want default", got)
+	}
+	if got := sources["password"]; got.Kind != SourceUnset {
+		t.Errorf("password source = %+v, want unset", got)
+	}
+}
+
+func TestUnmarshalValidationErrorsNameEnv(t *testing.T) {
+	t.Setenv("TESTSVC_KAFKA_BROKERS", "kafka")
+
+	var cfg testConfig
+	err := NewLoader("testsvc").Unmarshal(&cfg)
+
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) {
+		t.Fatalf("error = %v, want ValidationErrors", err)
+	}
+	want := []string{
+		"server.host (TESTSVC_SERVER_HOST): is required",
+		"kafka.brokers (TESTSVC_KAFKA_BROKERS): invalid host:port address: kafka",
+	}
+	if len(verrs) != len(want) {
+		t.Fatalf("got %d errors, want %d:\n%v", len(verrs), len(want), err)
+	}
+	for i, w := range want {
+		if got := verrs[i].Error(); got != w {
+			t.Errorf("error[%d] = %q, want %q", i, got, w)
+		}
+	}
+}
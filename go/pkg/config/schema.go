@@ -0,0 +1,242 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ServicePrefixes - префиксы ENV сервисов, читающих AppConfig (config.NewLoader("CHAT")).
+// В yaml/toml слоях они же (в нижнем регистре) - имена секций с переопределениями сервиса.
+var ServicePrefixes = []string{"SHELL", "LANDING", "CHAT", "NOTIFICATION", "GREETER"}
+
+// fieldInfo - описание листового поля AppConfig для схемы и справочника
+type fieldInfo struct {
+	key      string
+	typ      reflect.Type
+	def      string
+	hasDef   bool
+	validate string
+	secret   bool
+}
+
+// appConfigFields возвращает листовые поля AppConfig в порядке объявления
+func appConfigFields() []fieldInfo {
+	var out []fieldInfo
+	walkFields(reflect.ValueOf(AppConfig{}), nil, func(key string, field reflect.StructField, value reflect.Value) {
+		def, hasDef := field.Tag.Lookup("default")
+		out = append(out, fieldInfo{
+			key:      key,
+			typ:      field.Type,
+			def:      def,
+			hasDef:   hasDef,
+			validate: field.Tag.Get("validate"),
+			secret:   isSecret(field),
+		})
+	})
+	return out
+}
+
+// typeName - тип поля в терминах файлов конфигурации
+func typeName(t reflect.Type) string {
+	if t == reflect.TypeOf(time.Duration(0)) {
+		return "duration"
+	}
+	switch t.Kind() {
+	case reflect.Slice:
+		return "list of " + typeName(t.Elem())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	default:
+		return "string"
+	}
+}
+
+// valueSchema строит JSON Schema значения по типу и правилам validate
+func valueSchema(t reflect.Type, validate string) map[string]interface{} {
+	s := make(map[string]interface{})
+
+	switch {
+	case t == reflect.TypeOf(time.Duration(0)):
+		s["type"] = "string"
+		s["pattern"] = `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+	case t.Kind() == reflect.Slice:
+		// В env-файлах список задается строкой через запятую
+		s["type"] = []string{"array", "string"}
+		s["items"] = valueSchema(t.Elem(), validate)
+		return s
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64:
+		s["type"] = "integer"
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		s["type"] = "number"
+	case t.Kind() == reflect.Bool:
+		s["type"] = "boolean"
+	default:
+		s["type"] = "string"
+	}
+
+	for _, rule := range strings.Split(validate, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "port":
+			// В yaml порт естественно пишется числом
+			s["type"] = []string{"string", "integer"}
+			s["pattern"] = `^[0-9]+$`
+		case "hostport":
+			s["pattern"] = `^[^:]*:[0-9]+$`
+		case "url":
+			s["format"] = "uri"
+		case "oneof":
+			s["enum"] = strings.Fields(arg)
+		case "min":
+			if n, err := strconv.ParseFloat(arg, 64); err == nil {
+				s["minimum"] = n
+			}
+		case "max":
+			if n, err := strconv.ParseFloat(arg, 64); err == nil {
+				s["maximum"] = n
+			}
+		}
+	}
+	return s
+}
+
+// JSONSchema возвращает JSON Schema (draft 2020-12) для yaml/toml слоев конфигурации:
+// секции AppConfig на верхнем уровне и те же секции внутри секции сервиса (chat:, notification:, ...).
+func JSONSchema() map[string]interface{} {
+	sections := make(map[string]map[string]interface{})
+	var order []string
+
+	for _, f := range appConfigFields() {
+		section, name, _ := strings.Cut(f.key, ".")
+		if _, ok := sections[section]; !ok {
+			sections[section] = map[string]interface{}{
+				"type":                 "object",
+				"properties":           make(map[string]interface{}),
+				"additionalProperties": false,
+			}
+			order = append(order, section)
+		}
+
+		prop := valueSchema(f.typ, f.validate)
+		envs := make([]string, len(ServicePrefixes))
+		for i, prefix := range ServicePrefixes {
+			envs[i] = prefix + "_" + strings.ToUpper(strings.ReplaceAll(f.key, ".", "_"))
+		}
+		prop["description"] = "ENV: " + strings.Join(envs, ", ")
+		if f.hasDef {
+			prop["default"] = f.def
+			if n, err := strconv.Atoi(f.def); err == nil && prop["type"] == "integer" {
+				prop["default"] = n
+			}
+		}
+		if f.secret {
+			prop["writeOnly"] = true
+		}
+
+		props := sections[section]["properties"].(map[string]interface{})
+		props[name] = prop
+		if f.typ.Kind() == reflect.String {
+			// Путь к файлу со значением, см. applySecretFiles
+			props[name+strings.ToLower(secretFileSuffix)] = map[string]interface{}{
+				"type":        "string",
+				"description": "Path to a file with the value of " + name,
+			}
+		}
+	}
+
+	defs := make(map[string]interface{})
+	overrides := make(map[string]interface{})
+	for _, section := range order {
+		defs[section] = sections[section]
+		overrides[section] = map[string]interface{}{"$ref": "#/$defs/" + section}
+	}
+
+	root := make(map[string]interface{})
+	for section, ref := range overrides {
+		root[section] = ref
+	}
+	for _, prefix := range ServicePrefixes {
+		root[strings.ToLower(prefix)] = map[string]interface{}{
+			"type":                 "object",
+			"description":          "Overrides for " + prefix + " service",
+			"properties":           overrides,
+			"additionalProperties": false,
+		}
+	}
+
+	return map[string]interface{}{
+		"$schema":              "https://json-schema.org/draft/2020-12/schema",
+		"title":                "AppConfig",
+		"description":          "Config layer file (base, <APP_ENV>, <service>, local) in yaml/toml format",
+		"type":                 "object",
+		"properties":           root,
+		"additionalProperties": false,
+		"$defs":                defs,
+	}
+}
+
+// WriteSchema печатает JSONSchema с отступами
+func WriteSchema(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(JSONSchema())
+}
+
+// WriteReference печатает markdown-справочник ключей AppConfig
+func WriteReference(w io.Writer) error {
+	var b strings.Builder
+
+	b.WriteString("# Справочник конфигурации\n\n")
+	b.WriteString("<!-- Сгенерировано из config.AppConfig: just config-docs. Не редактировать вручную. -->\n\n")
+	b.WriteString("Переменная окружения = префикс сервиса + ключ в верхнем регистре, точки заменяются на `_`: ")
+	b.WriteString("`kafka.brokers` -> `CHAT_KAFKA_BROKERS`.\n\n")
+	b.WriteString("Префиксы: ")
+	for i, prefix := range ServicePrefixes {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "`%s_`", prefix)
+	}
+	b.WriteString(".\n\n")
+	b.WriteString("Строковое поле можно передать файлом: `<ENV>_FILE` (в yaml/toml - `<ключ>_file`). ")
+	b.WriteString("Значения `secret` маскируются в логах, `--print-config` и `/admin/config`.\n")
+
+	section := ""
+	for _, f := range appConfigFields() {
+		s, _, _ := strings.Cut(f.key, ".")
+		if s != section {
+			section = s
+			fmt.Fprintf(&b, "\n## %s\n\n", section)
+			b.WriteString("| Ключ | ENV | Тип | Default | Validate | Secret |\n")
+			b.WriteString("|------|-----|-----|---------|----------|--------|\n")
+		}
+
+		env := "`<PREFIX>_" + strings.ToUpper(strings.ReplaceAll(f.key, ".", "_")) + "`"
+		def := ""
+		if f.hasDef {
+			def = "`" + f.def + "`"
+		}
+		validate := ""
+		if f.validate != "" {
+			validate = "`" + f.validate + "`"
+		}
+		secret := ""
+		if f.secret {
+			secret = "да"
+		}
+		fmt.Fprintf(&b, "| `%s` | %s | %s | %s | %s | %s |\n", f.key, env, typeName(f.typ), def, validate, secret)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RedactedValue подставляется вместо значений полей с тегом secret:"true"
+// во всех дампах конфигурации (Redact, логи через slog, ошибки валидации).
+const RedactedValue = "[REDACTED]"
+
+// secretFileSuffix - суффикс переменной с путем к файлу значения (Docker/K8s secrets):
+// CHAT_DATABASE_PASSWORD_FILE=/var/run/secrets/db/password
+const secretFileSuffix = "_FILE"
+
+// secretRefreshInterval - как часто Watch перечитывает файлы *_FILE.
+// Kubelet обновляет смонтированные секреты с задержкой порядка минуты.
+const secretRefreshInterval = time.Minute
+
+// isSecret проверяет тег secret:"true"
+func isSecret(field reflect.StructField) bool {
+	secret, _ := strconv.ParseBool(field.Tag.Get("secret"))
+	return secret
+}
+
+// walkFields обходит листовые поля структуры с тегом mapstructure.
+// key - полный ключ конфигурации (database.password).
+func walkFields(v reflect.Value, parts []string, fn func(key string, field reflect.StructField, value reflect.Value)) {
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, ok := field.Tag.Lookup("mapstructure")
+		if !ok {
+			continue
+		}
+
+		path := append(parts[:len(parts):len(parts)], name)
+		if field.Type.Kind() == reflect.Struct {
+			walkFields(v.Field(i), path, fn)
+			continue
+		}
+
+		fn(strings.Join(path, "."), field, v.Field(i))
+	}
+}
+
+// Redact возвращает конфигурацию в виде вложенной map по ключам mapstructure
+// с замаскированными секретами. Любой дамп конфигурации должен идти через эту функцию.
+func Redact(cfg interface{}) map[string]interface{} {
+	out := make(map[string]interface{})
+	walkFields(reflect.ValueOf(cfg), nil, func(key string, field reflect.StructField, value reflect.Value) {
+		parts := strings.Split(key, ".")
+		node := out
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := node[part].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				node[part] = child
+			}
+			node = child
+		}
+		node[parts[len(parts)-1]] = redactedValue(field, value)
+	})
+	return out
+}
+
+func redactedValue(field reflect.StructField, value reflect.Value) interface{} {
+	if isSecret(field) && !value.IsZero() {
+		return RedactedValue
+	}
+	// Длительности в дампах читаемее как "10s", чем в наносекундах
+	if d, ok := value.Interface().(time.Duration); ok {
+		return d.String()
+	}
+	return value.Interface()
+}
+
+// logValue строит slog.Value с замаскированными секретами
+func logValue(cfg interface{}) slog.Value {
+	return slog.AnyValue(Redact(cfg))
+}
+
+// LogValue реализует slog.LogValuer: конфигурация в логах всегда без секретов
+func (c AppConfig) LogValue() slog.Value { return logValue(c) }
+
+// LogValue реализует slog.LogValuer: пароль маскируется
+func (c DatabaseConfig) LogValue() slog.Value { return logValue(c) }
+
+// LogValue реализует slog.LogValuer: пароль маскируется
+func (c RedisConfig) LogValue() slog.Value { return logValue(c) }
+
+// LogValue реализует slog.LogValuer: URI может содержать учетные данные
+func (c MongoConfig) LogValue() slog.Value { return logValue(c) }
+
+// applySecretFiles подставляет значения из файлов, указанных в <ENV>_FILE.
+// Переменная берется из окружения или из слоев файлов конфигурации.
+// *_FILE имеет приоритет над обычным значением ключа. Поддерживаются только строковые поля.
+// Вызывается под l.mu.
+func (l *Loader) applySecretFiles(cfg interface{}, sources map[string]Source) ValidationErrors {
+	var errs ValidationErrors
+	hasSecretFiles := false
+
+	walkFields(reflect.ValueOf(cfg), nil, func(key string, field reflect.StructField, value reflect.Value) {
+		fileEnv := l.envKey(key) + secretFileSuffix
+
+		path := os.Getenv(fileEnv)
+		if path == "" {
+			path = l.fileSecretPath(key)
+		}
+		if path == "" {
+			return
+		}
+		hasSecretFiles = true
+
+		if value.Kind() != reflect.String {
+			errs = append(errs, FieldError{Key: key, Env: fileEnv, Err: fmt.Errorf("%s is supported only for string fields", secretFileSuffix)})
+			return
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, FieldError{Key: key, Env: fileEnv, Err: fmt.Errorf("failed to read secret file: %w", err)})
+			return
+		}
+
+		// Файлы секретов часто заканчиваются переводом строки (echo > file)
+		value.SetString(strings.TrimRight(string(data), "\r\n"))
+		sources[key] = Source{Kind: SourceSecretFile, Origin: fileEnv + "=" + path}
+	})
+
+	if hasSecretFiles {
+		l.hasSecretFiles = true
+	}
+	return errs
+}
@@ -0,0 +1,231 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeSecret пишет файл секрета во временную директорию
+func writeSecret(t *testing.T, value string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte(value), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSecretFilePrecedence(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        map[string]string
+		layers     map[string]string
+		secret     string // содержимое файла секрета; путь подставляется вместо {file}
+		want       string
+		wantSource string
+	}{
+		{
+			name:       "file over plain env",
+			env:        map[string]string{"TESTSVC_DATABASE_PASSWORD": "from-env", "TESTSVC_DATABASE_PASSWORD_FILE": "{file}"},
+			secret:     "from-file\n",
+			want:       "from-file",
+			wantSource: SourceSecretFile,
+		},
+		{
+			name:       "file path from config layer",
+			env:        map[string]string{"TESTSVC_DATABASE_PASSWORD": "from-env"},
+			layers:     map[string]string{"dev.env": "TESTSVC_DATABASE_PASSWORD_FILE={file}\n"},
+			secret:     "from-layer-file",
+			want:       "from-layer-file",
+			wantSource: SourceSecretFile,
+		},
+		{
+			name:       "file path from yaml layer",
+			layers:     map[string]string{"base.yaml": "database:\n  password_file: {file}\n"},
+			secret:     "from-yaml-file\r\n",
+			want:       "from-yaml-file",
+			wantSource: SourceSecretFile,
+		},
+		{
+			name:       "plain env without file",
+			env:        map[string]string{"TESTSVC_DATABASE_PASSWORD": "from-env"},
+			want:       "from-env",
+			wantSource: SourceEnv,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeSecret(t, tt.secret)
+			layers := make(map[string]string, len(tt.layers))
+			for name, content := range tt.layers {
+				layers[name] = strings.ReplaceAll(content, "{file}", path)
+			}
+			writeLayers(t, "dev", layers)
+			for k, v := range tt.env {
+				t.Setenv(k, strings.ReplaceAll(v, "{file}", path))
+			}
+
+			l := NewLoader("testsvc")
+			if err := l.Load(); err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			var cfg AppConfig
+			if err := l.Unmarshal(&cfg); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if cfg.Database.Password != tt.want {
+				t.Errorf("password = %q, want %q", cfg.Database.Password, tt.want)
+			}
+			if got := l.sources["database.password"].Kind; got != tt.wantSource {
+				t.Errorf("source = %s, want %s", got, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestSecretFileErrors(t *testing.T) {
+	writeLayers(t, "dev", nil)
+	t.Setenv("TESTSVC_DATABASE_PASSWORD_FILE", filepath.Join(t.TempDir(), "missing"))
+	t.Setenv("TESTSVC_SERVER_READ_TIMEOUT_FILE", writeSecret(t, "15"))
+
+	var cfg AppConfig
+	err := NewLoader("testsvc").Unmarshal(&cfg)
+	if err == nil {
+		t.Fatal("expected errors")
+	}
+	for _, want := range []string{
+		"database.password (TESTSVC_DATABASE_PASSWORD_FILE): failed to read secret file",
+		"server.read_timeout (TESTSVC_SERVER_READ_TIMEOUT_FILE): _FILE is supported only for string fields",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %v, want containing %q", err, want)
+		}
+	}
+}
+
+func TestSecretFileRotation(t *testing.T) {
+	writeLayers(t, "dev", nil)
+	path := writeSecret(t, "v1")
+	t.Setenv("TESTSVC_DATABASE_PASSWORD_FILE", path)
+
+	l := NewLoader("testsvc")
+	if err := l.Load(); err != nil {
+		t.Fatal(err)
+	}
+	var cfg AppConfig
+	if err := l.Unmarshal(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if !l.hasSecretFiles {
+		t.Fatal("hasSecretFiles = false, want periodic refresh enabled")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan change, 1)
+	if err := l.Watch(ctx, func(old, new AppConfig) { changes <- change{old, new} }); err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+
+	// Kubelet подменил секрет; reload - то, что делает тикер secretRefreshInterval
+	if err := os.WriteFile(path, []byte("v2"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	l.reload()
+
+	select {
+	case c := <-changes:
+		if c.old.Database.Password != "v1" || c.new.Database.Password != "v2" {
+			t.Errorf("password %q -> %q, want v1 -> v2", c.old.Database.Password, c.new.Database.Password)
+		}
+	default:
+		t.Fatal("rotated secret not delivered to subscribers")
+	}
+}
+
+func TestSecretsMasked(t *testing.T) {
+	writeLayers(t, "dev", nil)
+	t.Setenv("TESTSVC_DATABASE_PASSWORD", "db-pass")
+	t.Setenv("TESTSVC_SERVER_ADMIN_TOKEN", "admin-token")
+	t.Setenv("TESTSVC_TELEMETRY_OTEL_HEADERS", "x-api-key=otel-key")
+
+	l := NewLoader("testsvc")
+	if err := l.Load(); err != nil {
+		t.Fatal(err)
+	}
+	var cfg AppConfig
+	if err := l.Unmarshal(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	secrets := []string{"db-pass", "admin-token", "otel-key"}
+
+	t.Run("LogValue", func(t *testing.T) {
+		var buf bytes.Buffer
+		log := slog.New(slog.NewJSONHandler(&buf, nil))
+		log.Info("config", "config", cfg, "database", cfg.Database)
+		assertMasked(t, buf.String(), secrets)
+
+		var out struct {
+			Config struct {
+				Database struct {
+					Password string `json:"password"`
+					Port     string `json:"port"`
+				} `json:"database"`
+			} `json:"config"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+			t.Fatal(err)
+		}
+		if out.Config.Database.Password != RedactedValue || out.Config.Database.Port != "5432" {
+			t.Errorf("database = %+v, want masked password and port 5432", out.Config.Database)
+		}
+	})
+
+	t.Run("PrintConfig", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := l.PrintConfig(&buf, &cfg); err != nil {
+			t.Fatal(err)
+		}
+		assertMasked(t, buf.String(), secrets)
+		if !strings.Contains(buf.String(), "TESTSVC_DATABASE_PASSWORD") {
+			t.Errorf("print-config lacks password row:\n%s", buf.String())
+		}
+	})
+
+	t.Run("Handler", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		l.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/config", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d", rec.Code)
+		}
+		assertMasked(t, rec.Body.String(), secrets)
+	})
+
+	t.Run("empty secret stays empty", func(t *testing.T) {
+		redactedCfg := Redact(AppConfig{})
+		if got := redactedCfg["database"].(map[string]interface{})["password"]; got != "" {
+			t.Errorf("empty password = %v, want empty", got)
+		}
+	})
+}
+
+func assertMasked(t *testing.T, out string, secrets []string) {
+	t.Helper()
+	for _, s := range secrets {
+		if strings.Contains(out, s) {
+			t.Errorf("secret %q leaked:\n%s", s, out)
+		}
+	}
+	if !strings.Contains(out, RedactedValue) {
+		t.Errorf("output has no %s:\n%s", RedactedValue, out)
+	}
+}
@@ -1,56 +1,132 @@
 package config
 
-// DatabaseConfig общая структура для конфигурации БД
+import "time"
+
+// DatabaseConfig общая структура для конфигурации БД (PostgreSQL).
+// Набор и порядок полей совпадает с database.Config: main передает database.Config(cfg.Database).
 type DatabaseConfig struct {
-	Host     string `mapstructure:"host"`
-	Port     string `mapstructure:"port"`
+	Driver   string `mapstructure:"driver" default:"pgx"` // Драйвер database/sql, регистрируется импортом в main сервиса
+	Host     string `mapstructure:"host"`                 // Пустой, если сервис не использует БД
+	Port     string `mapstructure:"port" default:"5432" validate:"port"`
 	User     string `mapstructure:"user"`
-	Password string `mapstructure:"password"`
+	Password string `mapstructure:"password" secret:"true"` // Можно передать файлом: <PREFIX>_DATABASE_PASSWORD_FILE
 	Database string `mapstructure:"database"`
-	SSLMode  string `mapstructure:"sslmode"`
+	SSLMode  string `mapstructure:"sslmode" default:"disable" validate:"oneof=disable allow prefer require verify-ca verify-full"`
+
+	MaxOpenConns    int           `mapstructure:"max_open_conns" default:"10"`
+	MaxIdleConns    int           `mapstructure:"max_idle_conns" default:"5"`
+	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime" default:"30m"` // Пересоздание соединений (ротация на стороне PgBouncer/LB)
+	ConnMaxIdleTime time.Duration `mapstructure:"conn_max_idle_time" default:"5m"`
 }
 
 // RedisConfig конфигурация Redis
 type RedisConfig struct {
 	Host     string `mapstructure:"host"`
 	Port     string `mapstructure:"port"`
-	Password string `mapstructure:"password"`
+	Password string `mapstructure:"password" secret:"true"`
 	DB       int    `mapstructure:"db"`
 }
 
 // MongoConfig конфигурация MongoDB
 type MongoConfig struct {
-	URI      string `mapstructure:"uri"`
+	URI      string `mapstructure:"uri" secret:"true"` // Может содержать логин/пароль
 	Database string `mapstructure:"database"`
 }
 
 // LogConfig конфигурация логирования
 type LogConfig struct {
-	Level  string `mapstructure:"level"`
-	Format string `mapstructure:"format"`
+	Level  string `mapstructure:"level" default:"info" validate:"oneof=debug info warn error"`
+	Format string `mapstructure:"format" default:"json" validate:"oneof=json text logfmt"` // text - цветной вывод для локальной разработки
+	// Подстроки имен ключей (без учета регистра), значения которых маскируются в логах как [REDACTED].
+	// JWT и номера карт маскируются в любых значениях независимо от этого списка.
+	RedactKeys []string `mapstructure:"redact_keys" default:"password,passwd,secret,token,authorization,cookie,api_key,email"`
+
+	// Сэмплирование горячих сообщений: по каждому сообщению в секунду выводятся первые sample_initial,
+	// затем каждое sample_thereafter-е; раз в sample_summary - сводка пропущенных. Error выводятся всегда.
+	// sample_initial=0 отключает сэмплирование.
+	SampleInitial    int           `mapstructure:"sample_initial" default:"10"`
+	SampleThereafter int           `mapstructure:"sample_thereafter" default:"100"`
+	SampleSummary    time.Duration `mapstructure:"sample_summary" default:"1m"`
 }
 
 // TelemetryConfig конфигурация observability
 type TelemetryConfig struct {
-	OtelEndpoint      string `mapstructure:"otel_endpoint"`
-	PyroscopeEndpoint string `mapstructure:"pyroscope_endpoint"`
+	OtelEndpoint      string `mapstructure:"otel_endpoint" default:"localhost:4317" validate:"hostport"`
+	PyroscopeEndpoint string `mapstructure:"pyroscope_endpoint" validate:"url"`
 	ServiceName       string `mapstructure:"service_name"` // Имя сервиса для трейсинга
+
+	// Сигналы, которые поднимает telemetry.Setup. Профилирование включается непустым pyroscope_endpoint.
+	Traces  bool `mapstructure:"traces" default:"true"`  // OTLP на otel_endpoint
+	Metrics bool `mapstructure:"metrics" default:"true"` // Prometheus, /metrics
+	// Дублировать логи в OTLP с trace_id/span_id из контекста. stdout не меняется:
+	// если его уже собирают в то же хранилище, записи задвоятся.
+	Logs bool `mapstructure:"logs"`
+	// Сколько ждать отправки буферов при остановке
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout" default:"5s"`
+
+	// Сэмплирование трейсов. Входящий traceparent уважается всегда, стратегия решает за корневые спаны:
+	// always, ratio (доля sample_ratio), ratelimit (не больше sample_rate трейсов в секунду).
+	SampleStrategy string  `mapstructure:"sample_strategy" default:"always" validate:"oneof=always ratio ratelimit"`
+	SampleRatio    float64 `mapstructure:"sample_ratio" default:"1" validate:"min=0,max=1"`
+	SampleRate     float64 `mapstructure:"sample_rate" default:"100" validate:"min=0"`
+	// Маршруты, которые не трейсятся никогда: "/health" - точно, "/admin/" - по префиксу.
+	// gRPC - по имени спана без ведущего слэша: "grpc.health.v1.Health/" (пробы k8s и Envoy)
+	SampleDropRoutes []string `mapstructure:"sample_drop_routes" default:"/health,/livez,/readyz,/metrics,grpc.health.v1.Health/"`
+	// Отправлять спаны с ошибкой (status Error), даже если трейс не отобран
+	SampleErrors bool `mapstructure:"sample_errors" default:"true"`
+
+	// Куда отправлять трейсы: otlp-grpc, otlp-http (на otel_endpoint), stdout (дерево спанов в консоль),
+	// file (JSONL в export_file). Логи (logs=true) уходят только в OTLP.
+	Exporter string `mapstructure:"exporter" default:"otlp-grpc" validate:"oneof=otlp-grpc otlp-http stdout file"`
+	// Заголовки OTLP через запятую: "x-api-key=...,x-scope-orgid=tenant-1". Можно передать файлом: <PREFIX>_TELEMETRY_OTEL_HEADERS_FILE
+	OtelHeaders string `mapstructure:"otel_headers" secret:"true"`
+	// Без TLS до коллектора. С false - системные корневые сертификаты или otel_ca_file, mTLS - otel_cert_file + otel_key_file
+	OtelInsecure bool   `mapstructure:"otel_insecure" default:"true"`
+	OtelCAFile   string `mapstructure:"otel_ca_file"`
+	OtelCertFile string `mapstructure:"otel_cert_file"`
+	OtelKeyFile  string `mapstructure:"otel_key_file"`
+	// Файловый экспортер: ротация при превышении export_file_max_size мегабайт, хранится export_file_backups старых файлов
+	ExportFile        string `mapstructure:"export_file" default:"traces.jsonl"`
+	ExportFileMaxSize int    `mapstructure:"export_file_max_size" default:"100" validate:"min=1"`
+	ExportFileBackups int    `mapstructure:"export_file_backups" default:"3" validate:"min=0"`
+
+	// Профили для Pyroscope и /debug/pprof. mutex_* и block_* включают выборку блокировок в рантайме -
+	// заметная нагрузка, по умолчанию выключены.
+	ProfileTypes []string `mapstructure:"profile_types" default:"cpu,alloc_objects,alloc_space,inuse_objects,inuse_space,goroutines" validate:"oneof=cpu alloc_objects alloc_space inuse_objects inuse_space goroutines mutex_count mutex_duration block_count block_duration"`
+	// net/http/pprof на отдельном адресе (localhost:6060); пусто - выключен.
+	// Запросы - с заголовком Authorization: Bearer <pprof_token>, без токена listener не поднимается.
+	PprofAddr  string `mapstructure:"pprof_addr" validate:"hostport"`
+	PprofToken string `mapstructure:"pprof_token" secret:"true"` // Можно передать файлом: <PREFIX>_TELEMETRY_PPROF_TOKEN_FILE
 }
 
 // ServerConfig базовая конфигурация HTTP/GRPC сервера
 type ServerConfig struct {
-	HTTPPort     string `mapstructure:"http_port"`
-	GRPCPort     string `mapstructure:"grpc_port"`
+	HTTPPort     string `mapstructure:"http_port" default:"8081" validate:"required,port"`
+	GRPCPort     string `mapstructure:"grpc_port" validate:"port"` // Пустой, если сервис не поднимает gRPC (shell)
 	StaticDir    string `mapstructure:"static_dir"`
-	ReadTimeout  int    `mapstructure:"read_timeout"`
-	WriteTimeout int    `mapstructure:"write_timeout"`
+	ReadTimeout  int    `mapstructure:"read_timeout" default:"15"`
+	WriteTimeout int    `mapstructure:"write_timeout" default:"15"`
+	// Сколько ждать остановки всех компонентов (app.Runner) после SIGTERM; меньше terminationGracePeriodSeconds пода
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout" default:"15s"`
+	// Сколько после SIGTERM отвечать 503 на /readyz (NOT_SERVING в gRPC) до остановки серверов: пробы k8s
+	// и health check Envoy успевают вывести под из балансировки. Входит в shutdown_timeout, больше периода readinessProbe
+	ShutdownDrain time.Duration `mapstructure:"shutdown_drain" default:"5s"`
+	// Таймаут одной проверки /readyz и сколько переиспользовать результат (пробы k8s и Envoy идут часто)
+	HealthTimeout  time.Duration `mapstructure:"health_timeout" default:"2s"`
+	HealthCacheTTL time.Duration `mapstructure:"health_cache_ttl" default:"5s"`
+	// /admin/* (конфигурация, фич-флаги, уровень логов) - с заголовком Authorization: Bearer <admin_token>;
+	// пусто - эндпоинты выключены
+	AdminToken string `mapstructure:"admin_token" secret:"true"` // Можно передать файлом: <PREFIX>_SERVER_ADMIN_TOKEN_FILE
 }
 
 // KafkaConfig конфигурация для брокера сообщений
 type KafkaConfig struct {
-	Brokers []string `mapstructure:"brokers"`
-	Topic   string   `mapstructure:"topic"`
+	Brokers []string `mapstructure:"brokers" validate:"hostport"` // Правило применяется к каждому брокеру
+	Topic   string   `mapstructure:"topic" default:"chat-messages"`
 	GroupID string   `mapstructure:"group_id"`
+
+	WriteTimeout time.Duration `mapstructure:"write_timeout" default:"10s"` // Таймаут записи продьюсера
+	MaxWait      time.Duration `mapstructure:"max_wait" default:"500ms"`    // Сколько консьюмер ждет наполнения батча
 }
 
 // ServicesConfig адреса зависимых микросервисов (Service Discovery)
@@ -66,6 +142,7 @@ type AppConfig struct {
 	Log       LogConfig       `mapstructure:"log"`
 	Telemetry TelemetryConfig `mapstructure:"telemetry"`
 	Kafka     KafkaConfig     `mapstructure:"kafka"`
+	Database  DatabaseConfig  `mapstructure:"database"`
 	Services  ServicesConfig  `mapstructure:"services"`
 	// Можно добавлять специфичные секции, если нужно
 }
@@ -1,9 +1,13 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net"
 	"net/url"
+	"reflect"
 	"strconv"
+	"strings"
 )
 
 // Validator предоставляет методы для валидации конфигурации
@@ -50,6 +54,20 @@ func (v *Validator) ValidateURL(rawURL string) error {
 	return nil
 }
 
+// ValidateHostPort проверяет адрес вида host:port (gRPC эндпоинты, брокеры Kafka)
+func (v *Validator) ValidateHostPort(addr string) error {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid host:port address: %s", addr)
+	}
+
+	if host == "" {
+		return fmt.Errorf("address must have a host: %s", addr)
+	}
+
+	return v.ValidatePort(port)
+}
+
 // ValidateRequired проверяет что значение не пустое
 func (v *Validator) ValidateRequired(value, fieldName string) error {
 	if value == "" {
@@ -67,3 +85,160 @@ func (v *Validator) ValidateOneOf(value string, allowed []string, fieldName stri
 	}
 	return fmt.Errorf("%s must be one of %v, got: %s", fieldName, allowed, value)
 }
+
+// ValidateRange проверяет числовую границу: rule "min" - value >= bound, "max" - value <= bound
+func (v *Validator) ValidateRange(rule, value, bound, fieldName string) error {
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("%s must be a number, got: %s", fieldName, value)
+	}
+	b, err := strconv.ParseFloat(bound, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s bound %q", rule, bound)
+	}
+	if rule == "min" && n < b {
+		return fmt.Errorf("%s must be >= %s, got: %s", fieldName, bound, value)
+	}
+	if rule == "max" && n > b {
+		return fmt.Errorf("%s must be <= %s, got: %s", fieldName, bound, value)
+	}
+	return nil
+}
+
+// FieldError ошибка валидации одного поля конфигурации
+type FieldError struct {
+	Key string // Ключ конфигурации, например server.http_port
+	Env string // Переменная окружения, из которой читается ключ (заполняет Loader)
+	Err error
+}
+
+func (e FieldError) Error() string {
+	if e.Env != "" {
+		return fmt.Sprintf("%s (%s): %v", e.Key, e.Env, e.Err)
+	}
+	return fmt.Sprintf("%s: %v", e.Key, e.Err)
+}
+
+func (e FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors список всех невалидных полей.
+// Возвращается целиком, чтобы под падал на старте с полным списком проблем, а не по одной.
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	lines := make([]string, 0, len(e)+1)
+	lines = append(lines, fmt.Sprintf("invalid config (%d errors):", len(e)))
+	for _, fe := range e {
+		lines = append(lines, "  - "+fe.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ValidateStruct проверяет поля структуры по тегу validate.
+// Поддерживаемые правила (через запятую): required, port, hostport, url, oneof=a b c, min=N, max=N.
+// Правила формата (port, hostport, url, oneof) пропускают пустые значения; min и max проверяют и нулевые
+// числа: 0 при min=1 - ошибка, а не "не задано".
+// Для слайсов правило применяется к каждому элементу.
+func (v *Validator) ValidateStruct(cfg interface{}) error {
+	var errs ValidationErrors
+	v.validateStruct(reflect.ValueOf(cfg), nil, &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (v *Validator) validateStruct(val reflect.Value, parts []string, errs *ValidationErrors) {
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name, ok := field.Tag.Lookup("mapstructure")
+		if !ok {
+			continue
+		}
+
+		path := append(parts[:len(parts):len(parts)], name)
+		fv := val.Field(i)
+
+		if field.Type.Kind() == reflect.Struct {
+			v.validateStruct(fv, path, errs)
+			continue
+		}
+
+		rules, ok := field.Tag.Lookup("validate")
+		if !ok {
+			continue
+		}
+
+		key := strings.Join(path, ".")
+		for _, rule := range strings.Split(rules, ",") {
+			rule = strings.TrimSpace(rule)
+			if err := v.applyRule(rule, key, fv); err != nil {
+				// Сообщения валидаторов содержат значение - для секретов его не раскрываем
+				if isSecret(field) {
+					err = fmt.Errorf("value does not satisfy %q (%s)", rule, RedactedValue)
+				}
+				*errs = append(*errs, FieldError{Key: key, Err: err})
+			}
+		}
+	}
+}
+
+func (v *Validator) applyRule(rule, key string, fv reflect.Value) error {
+	name, arg, _ := strings.Cut(rule, "=")
+
+	if name == "required" {
+		if fv.IsZero() || (fv.Kind() == reflect.Slice && fv.Len() == 0) {
+			return errors.New("is required")
+		}
+		return nil
+	}
+
+	var values []string
+	if fv.Kind() == reflect.Slice {
+		for j := 0; j < fv.Len(); j++ {
+			values = append(values, fmt.Sprint(fv.Index(j).Interface()))
+		}
+	} else if !fv.IsZero() || ((name == "min" || name == "max") && isNumber(fv.Kind())) {
+		values = append(values, fmt.Sprint(fv.Interface()))
+	}
+
+	for _, value := range values {
+		var err error
+		switch name {
+		case "port":
+			err = v.ValidatePort(value)
+		case "hostport":
+			err = v.ValidateHostPort(value)
+		case "url":
+			err = v.ValidateURL(value)
+		case "oneof":
+			err = v.ValidateOneOf(value, strings.Fields(arg), key)
+		case "min", "max":
+			err = v.ValidateRange(name, value, arg, key)
+		default:
+			return fmt.Errorf("unknown validation rule %q", name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func isNumber(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateStructRules(t *testing.T) {
+	type ports struct {
+		Port  int      `mapstructure:"port" validate:"port"`
+		Addr  string   `mapstructure:"addr" validate:"hostport"`
+		Addrs []string `mapstructure:"addrs" validate:"hostport"`
+	}
+
+	tests := []struct {
+		name    string
+		cfg     interface{}
+		wantErr string // пусто - конфигурация валидна
+	}{
+		{"required set", &struct {
+			Name string `mapstructure:"name" validate:"required"`
+		}{Name: "chat"}, ""},
+		{"required zero string", &struct {
+			Name string `mapstructure:"name" validate:"required"`
+		}{}, "name: is required"},
+		{"required empty slice", &struct {
+			List []string `mapstructure:"list" validate:"required"`
+		}{List: []string{}}, "list: is required"},
+		{"port valid", &ports{Port: 8080}, ""},
+		{"port zero skipped", &ports{}, ""},
+		{"port out of range", &ports{Port: 70000}, "port: port must be between 1 and 65535, got: 70000"},
+		{"hostport valid", &ports{Addr: "kafka:9092"}, ""},
+		{"hostport without port", &ports{Addr: "kafka"}, "addr: invalid host:port address: kafka"},
+		{"hostport without host", &ports{Addr: ":9092"}, "addr: address must have a host: :9092"},
+		{"hostport bad port", &ports{Addr: "kafka:0"}, "addr: port must be between 1 and 65535, got: 0"},
+		{"hostport slice element", &ports{Addrs: []string{"a:9092", "b"}}, "addrs: invalid host:port address: b"},
+		{"url valid", &struct {
+			URL string `mapstructure:"url" validate:"url"`
+		}{URL: "https://example.com/path"}, ""},
+		{"url empty skipped", &struct {
+			URL string `mapstructure:"url" validate:"url"`
+		}{}, ""},
+		{"url without scheme", &struct {
+			URL string `mapstructure:"url" validate:"url"`
+		}{URL: "example.com"}, "url: URL must have a scheme (http/https): example.com"},
+		{"url without host", &struct {
+			URL string `mapstructure:"url" validate:"url"`
+		}{URL: "http://"}, "url: URL must have a host: http://"},
+		{"oneof valid", &struct {
+			Level string `mapstructure:"level" validate:"oneof=debug info warn error"`
+		}{Level: "warn"}, ""},
+		{"oneof empty skipped", &struct {
+			Level string `mapstructure:"level" validate:"oneof=debug info"`
+		}{}, ""},
+		{"oneof invalid", &struct {
+			Level string `mapstructure:"level" validate:"oneof=debug info"`
+		}{Level: "trace"}, "level: level must be one of [debug info], got: trace"},
+		{"min valid", &struct {
+			N int `mapstructure:"n" validate:"min=1"`
+		}{N: 1}, ""},
+		{"min zero checked", &struct {
+			N int `mapstructure:"n" validate:"min=1"`
+		}{}, "n: n must be >= 1, got: 0"},
+		{"min zero allowed", &struct {
+			N int `mapstructure:"n" validate:"min=0"`
+		}{}, ""},
+		{"max valid", &struct {
+			Rate float64 `mapstructure:"rate" validate:"max=1"`
+		}{Rate: 0.5}, ""},
+		{"max exceeded", &struct {
+			Rate float64 `mapstructure:"rate" validate:"min=0,max=1"`
+		}{Rate: 1.5}, "rate: rate must be <= 1, got: 1.5"},
+		{"min negative", &struct {
+			Rate float64 `mapstructure:"rate" validate:"min=0,max=1"`
+		}{Rate: -0.1}, "rate: rate must be >= 0, got: -0.1"},
+		{"nested key", &struct {
+			Server struct {
+				Port int `mapstructure:"http_port" validate:"required,port"`
+			} `mapstructure:"server"`
+		}{}, "server.http_port: is required"},
+		{"unknown rule", &struct {
+			X string `mapstructure:"x" validate:"email"`
+		}{X: "a"}, `x: unknown validation rule "email"`},
+		{"field without mapstructure ignored", &struct {
+			X string `validate:"required"`
+		}{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewValidator().ValidateStruct(tt.cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var verrs ValidationErrors
+			if !errors.As(err, &verrs) {
+				t.Fatalf("error = %v, want ValidationErrors", err)
+			}
+			if len(verrs) != 1 {
+				t.Fatalf("got %d errors, want 1: %v", len(verrs), err)
+			}
+			if got := verrs[0].Error(); got != tt.wantErr {
+				t.Errorf("error = %q, want %q", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateStructRedactsSecrets(t *testing.T) {
+	cfg := struct {
+		Password string `mapstructure:"password" secret:"true" validate:"oneof=a b"`
+		Mode     string `mapstructure:"mode" validate:"oneof=a b"`
+	}{Password: "hunter2", Mode: "c"}
+
+	err := NewValidator().ValidateStruct(&cfg)
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	if strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("secret value leaked into error: %v", err)
+	}
+
+	verrs := err.(ValidationErrors)
+	want := []string{
+		`password: value does not satisfy "oneof=a b" ([REDACTED])`,
+		"mode: mode must be one of [a b], got: c",
+	}
+	if len(verrs) != len(want) {
+		t.Fatalf("got %d errors, want %d: %v", len(verrs), len(want), err)
+	}
+	for i, w := range want {
+		if got := verrs[i].Error(); got != w {
+			t.Errorf("error[%d] = %q, want %q", i, got, w)
+		}
+	}
+}
+
+func TestValidateStructAggregatesErrors(t *testing.T) {
+	cfg := struct {
+		Server struct {
+			Port int    `mapstructure:"http_port" validate:"required,port"`
+			Host string `mapstructure:"host" validate:"required"`
+		} `mapstructure:"server"`
+		Kafka struct {
+			Brokers []string `mapstructure:"brokers" validate:"required,hostport"`
+		} `mapstructure:"kafka"`
+		Rate float64 `mapstructure:"rate" validate:"max=1"`
+	}{Rate: 2}
+
+	err := NewValidator().ValidateStruct(&cfg)
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) {
+		t.Fatalf("error = %v, want ValidationErrors", err)
+	}
+
+	var keys []string
+	for _, fe := range verrs {
+		keys = append(keys, fe.Key)
+	}
+	if got, want := strings.Join(keys, ","), "server.http_port,server.host,kafka.brokers,rate"; got != want {
+		t.Errorf("keys = %s, want %s", got, want)
+	}
+	if !strings.HasPrefix(err.Error(), "invalid config (4 errors):\n  - server.http_port: is required") {
+		t.Errorf("unexpected message:\n%s", err)
+	}
+}
+
+func TestFieldErrorEnv(t *testing.T) {
+	fe := FieldError{Key: "server.http_port", Env: "CHAT_SERVER_HTTP_PORT", Err: errors.New("is required")}
+	if got, want := fe.Error(), "server.http_port (CHAT_SERVER_HTTP_PORT): is required"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
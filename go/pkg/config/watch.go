@@ -0,0 +1,229 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce - редакторы и kubelet пишут файл в несколько событий,
+// поэтому перечитываем один раз после затишья.
+const reloadDebounce = 200 * time.Millisecond
+
+type subscriber struct {
+	id int
+	fn func(old, new AppConfig)
+}
+
+// Watch подписывает fn на изменения конфигурации.
+// Loader следит за директорией со слоями конфигурации (см. Load) и при изменении, появлении
+// или удалении любого слоя заново вычисляет AppConfig (слои + ENV + default). Если используются секреты из *_FILE, они дополнительно
+// перечитываются раз в secretRefreshInterval (ротация секретов k8s). Подписчики вызываются только если новая конфигурация прошла
+// валидацию и отличается от текущей. Невалидные изменения отклоняются, текущая конфигурация сохраняется.
+//
+// Переменные окружения процесса не меняются после старта, поэтому ключи, заданные через ENV,
+// перекрывают файл и при перечитывании. Для ключей, которые нужно менять на лету
+// (log.level, kafka.topic, таймауты), значение должно задаваться только в файле.
+//
+// Требует предварительного Unmarshal(*AppConfig). Подписка снимается при отмене ctx;
+// наблюдение за файлом прекращается, когда не остается подписчиков.
+func (l *Loader) Watch(ctx context.Context, fn func(old, new AppConfig)) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.current == nil {
+		return errors.New("config: Watch requires Unmarshal(*AppConfig) first")
+	}
+
+	dir := ""
+	if len(l.files) > 0 {
+		dir = l.dir
+	}
+	if dir == "" && !l.hasSecretFiles {
+		return errors.New("config: no config file or secret files loaded, nothing to watch")
+	}
+
+	if l.stop == nil {
+		stop, err := l.startWatcher(dir, l.hasSecretFiles)
+		if err != nil {
+			return err
+		}
+		l.stop = stop
+	}
+
+	id := l.nextSub
+	l.nextSub++
+	l.subs = append(l.subs, subscriber{id: id, fn: fn})
+
+	go func() {
+		<-ctx.Done()
+		l.unsubscribe(id)
+	}()
+
+	return nil
+}
+
+func (l *Loader) unsubscribe(id int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i, sub := range l.subs {
+		if sub.id == id {
+			l.subs = append(l.subs[:i], l.subs[i+1:]...)
+			break
+		}
+	}
+	if len(l.subs) == 0 && l.stop != nil {
+		l.stop()
+		l.stop = nil
+	}
+}
+
+// startWatcher следит за директорией, а не за файлами: так видны новые слои (local.yaml),
+// а ConfigMap в k8s обновляется атомарной заменой симлинка ..data, и watch на файл теряется.
+// При refreshSecrets дополнительно запускается периодическое перечитывание.
+func (l *Loader) startWatcher(dir string, refreshSecrets bool) (func(), error) {
+	var watcher *fsnotify.Watcher
+	var events <-chan fsnotify.Event
+	var errs <-chan error
+
+	if dir != "" {
+		w, err := fsnotify.NewWatcher()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create config watcher: %w", err)
+		}
+
+		if err := w.Add(dir); err != nil {
+			_ = w.Close()
+			return nil, fmt.Errorf("failed to watch config dir %s: %w", dir, err)
+		}
+
+		watcher, events, errs = w, w.Events, w.Errors
+	}
+
+	var tick <-chan time.Time
+	var ticker *time.Ticker
+	if refreshSecrets {
+		ticker = time.NewTicker(secretRefreshInterval)
+		tick = ticker.C
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		var timer *time.Timer
+		defer func() {
+			if timer != nil {
+				timer.Stop()
+			}
+		}()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-tick:
+				l.reload()
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+
+				// Интересны только слои и служебные симлинки k8s (..data, ..2024_01_01_...).
+				// Лишний reload безопасен: без изменений подписчики не вызываются.
+				name := filepath.Base(event.Name)
+				if !l.isLayerFile(name) && !strings.HasPrefix(name, "..") {
+					continue
+				}
+
+				if timer == nil {
+					timer = time.AfterFunc(reloadDebounce, l.reload)
+				} else {
+					timer.Reset(reloadDebounce)
+				}
+			case err, ok := <-errs:
+				if !ok {
+					return
+				}
+				slog.Default().Warn("⚠️ Config watcher error", "error", err)
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		if ticker != nil {
+			ticker.Stop()
+		}
+		if watcher != nil {
+			_ = watcher.Close()
+		}
+	}, nil
+}
+
+// reload перечитывает слои и уведомляет подписчиков, если конфигурация изменилась.
+func (l *Loader) reload() {
+	l.mu.Lock()
+
+	files, err := l.readFiles()
+	if err != nil {
+		l.mu.Unlock()
+		slog.Default().Warn("⚠️ Config reload failed", "error", err)
+		return
+	}
+	prevFiles := l.files
+	l.files = files
+
+	var next AppConfig
+	sources := make(map[string]Source)
+	if err := l.decode(&next, sources); err != nil {
+		l.files = prevFiles
+		l.mu.Unlock()
+		slog.Default().Warn("⚠️ Config change rejected, keeping previous config", "error", err)
+		return
+	}
+
+	// Источник может смениться и без смены значения (ключ переехал из файла в ENV)
+	l.sources = sources
+
+	if reflect.DeepEqual(*l.current, next) {
+		l.mu.Unlock()
+		return
+	}
+
+	old := *l.current
+	l.current = &next
+	subs := append([]subscriber(nil), l.subs...)
+	l.mu.Unlock()
+
+	// Пишем только имена изменившихся ключей: значения (в т.ч. секреты) в лог не попадают
+	slog.Default().Info("🔄 Config reloaded", "changed_keys", strings.Join(changedKeys(old, next), ", "))
+
+	// Подписчики вызываются вне блокировки и в порядке подписки
+	for _, sub := range subs {
+		sub.fn(old, next)
+	}
+}
+
+// changedKeys возвращает ключи, значения которых отличаются
+func changedKeys(old, new AppConfig) []string {
+	oldValues := make(map[string]interface{})
+	walkFields(reflect.ValueOf(old), nil, func(key string, _ reflect.StructField, value reflect.Value) {
+		oldValues[key] = value.Interface()
+	})
+
+	var keys []string
+	walkFields(reflect.ValueOf(new), nil, func(key string, _ reflect.StructField, value reflect.Value) {
+		if !reflect.DeepEqual(oldValues[key], value.Interface()) {
+			keys = append(keys, key)
+		}
+	})
+	return keys
+}
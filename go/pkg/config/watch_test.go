@@ -0,0 +1,127 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type change struct{ old, new AppConfig }
+
+// watchConfig загружает AppConfig из dir и подписывается на изменения
+func watchConfig(t *testing.T, dir string) (*Loader, <-chan change) {
+	t.Helper()
+	l := NewLoader("testsvc")
+	if err := l.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	var cfg AppConfig
+	if err := l.Unmarshal(&cfg); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	changes := make(chan change, 10)
+	if err := l.Watch(ctx, func(old, new AppConfig) { changes <- change{old, new} }); err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	return l, changes
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func expectChange(t *testing.T, changes <-chan change) change {
+	t.Helper()
+	select {
+	case c := <-changes:
+		return c
+	case <-time.After(5 * time.Second):
+		t.Fatal("no config change delivered")
+		return change{}
+	}
+}
+
+func expectNoChange(t *testing.T, changes <-chan change) {
+	t.Helper()
+	select {
+	case c := <-changes:
+		t.Fatalf("unexpected config change: log.level %s -> %s", c.old.Log.Level, c.new.Log.Level)
+	case <-time.After(3 * reloadDebounce):
+	}
+}
+
+func TestWatchDebouncesAndPassesOldNew(t *testing.T) {
+	dir := writeLayers(t, "dev", map[string]string{
+		"base.yaml": "log:\n  level: info\n",
+	})
+	_, changes := watchConfig(t, dir)
+
+	// Серия записей в пределах reloadDebounce - один reload с последним значением
+	path := filepath.Join(dir, "local.yaml")
+	for _, level := range []string{"warn", "error", "debug"} {
+		writeFile(t, path, "log:\n  level: "+level+"\n")
+		time.Sleep(reloadDebounce / 10)
+	}
+
+	c := expectChange(t, changes)
+	if c.old.Log.Level != "info" || c.new.Log.Level != "debug" {
+		t.Errorf("log.level %s -> %s, want info -> debug", c.old.Log.Level, c.new.Log.Level)
+	}
+	expectNoChange(t, changes)
+}
+
+func TestWatchRejectsInvalidChange(t *testing.T) {
+	dir := writeLayers(t, "dev", map[string]string{
+		"base.yaml": "log:\n  level: info\n",
+	})
+	l, changes := watchConfig(t, dir)
+
+	path := filepath.Join(dir, "base.yaml")
+	writeFile(t, path, "log:\n  level: verbose\n")
+	expectNoChange(t, changes)
+
+	l.mu.Lock()
+	level := l.current.Log.Level
+	l.mu.Unlock()
+	if level != "info" {
+		t.Errorf("current log.level = %s after rejected change, want info", level)
+	}
+
+	// Следующее валидное изменение сравнивается с последней принятой конфигурацией
+	writeFile(t, path, "log:\n  level: warn\n")
+	c := expectChange(t, changes)
+	if c.old.Log.Level != "info" || c.new.Log.Level != "warn" {
+		t.Errorf("log.level %s -> %s, want info -> warn", c.old.Log.Level, c.new.Log.Level)
+	}
+}
+
+func TestWatchIgnoresUnchangedConfig(t *testing.T) {
+	dir := writeLayers(t, "dev", map[string]string{
+		"base.yaml": "log:\n  level: info\n",
+	})
+	_, changes := watchConfig(t, dir)
+
+	// Файл переписан, но значения те же; посторонние файлы не влияют
+	writeFile(t, filepath.Join(dir, "base.yaml"), "# comment\nlog:\n  level: info\n")
+	writeFile(t, filepath.Join(dir, "flags.yaml"), "flags: {}\n")
+	expectNoChange(t, changes)
+}
+
+func TestWatchRequiresUnmarshal(t *testing.T) {
+	writeLayers(t, "dev", map[string]string{"base.yaml": "log:\n  level: info\n"})
+	l := NewLoader("testsvc")
+	if err := l.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Watch(context.Background(), func(old, new AppConfig) {}); err == nil {
+		t.Error("expected error without Unmarshal")
+	}
+}
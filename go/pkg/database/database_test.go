@@ -0,0 +1,68 @@
+package database
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestDSN(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{
+			name: "plain",
+			cfg:  Config{Host: "db", Port: "5432", User: "app", Password: "secret", Database: "chat", SSLMode: "disable"},
+			want: "postgres://app:secret@db:5432/chat?sslmode=disable",
+		},
+		{
+			name: "special characters in password",
+			cfg:  Config{Host: "db", Port: "5432", User: "app", Password: "p@ss:w/rd?#%&=", Database: "chat"},
+			want: "postgres://app:p%40ss%3Aw%2Frd%3F%23%25&=@db:5432/chat",
+		},
+		{
+			name: "special characters in user",
+			cfg:  Config{Host: "db", User: "app@tenant", Password: "x", Database: "chat"},
+			want: "postgres://app%40tenant:x@db/chat",
+		},
+		{
+			name: "ipv6 host",
+			cfg:  Config{Host: "::1", Port: "5432", Database: "chat", SSLMode: "verify-full"},
+			want: "postgres://[::1]:5432/chat?sslmode=verify-full",
+		},
+		{
+			name: "no credentials",
+			cfg:  Config{Host: "db", Port: "5432", Database: "chat"},
+			want: "postgres://db:5432/chat",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dsn := tt.cfg.DSN()
+			if dsn != tt.want {
+				t.Errorf("DSN() = %q, want %q", dsn, tt.want)
+			}
+
+			// Драйвер разбирает DSN как URL: учетные данные должны вернуться без изменений
+			u, err := url.Parse(dsn)
+			if err != nil {
+				t.Fatalf("DSN is not a valid URL: %v", err)
+			}
+			if tt.cfg.User == "" {
+				if u.User != nil {
+					t.Errorf("user info = %v, want none", u.User)
+				}
+				return
+			}
+			password, _ := u.User.Password()
+			if u.User.Username() != tt.cfg.User || password != tt.cfg.Password {
+				t.Errorf("parsed credentials = %q:%q, want %q:%q", u.User.Username(), password, tt.cfg.User, tt.cfg.Password)
+			}
+			if u.Hostname() != tt.cfg.Host || u.Path != "/"+tt.cfg.Database {
+				t.Errorf("parsed host/path = %s%s", u.Hostname(), u.Path)
+			}
+		})
+	}
+}
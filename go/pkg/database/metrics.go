@@ -0,0 +1,69 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// registerPoolMetrics экспортирует sql.DBStats как наблюдаемые метрики (имена по OTel semconv db.client.connections.*).
+// Значения снимаются при каждом сборе (scrape /metrics), отдельной горутины нет.
+func registerPoolMetrics(db *sql.DB, cfg Config) error {
+	meter := otel.Meter("database")
+	pool := attribute.String("pool.name", cfg.address()+"/"+cfg.Database)
+
+	usage, err := meter.Int64ObservableUpDownCounter("db.client.connections.usage",
+		metric.WithDescription("Connections in the pool by state (idle, used)"),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create database metric: %w", err)
+	}
+	maxConns, err := meter.Int64ObservableUpDownCounter("db.client.connections.max",
+		metric.WithDescription("Maximum number of open connections allowed (0 - unlimited)"),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create database metric: %w", err)
+	}
+	waits, err := meter.Int64ObservableCounter("db.client.connections.waits",
+		metric.WithDescription("Total number of times a query waited for a free connection"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create database metric: %w", err)
+	}
+	waitTime, err := meter.Float64ObservableCounter("db.client.connections.wait_time",
+		metric.WithDescription("Total time spent waiting for a free connection"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create database metric: %w", err)
+	}
+	closed, err := meter.Int64ObservableCounter("db.client.connections.closed",
+		metric.WithDescription("Connections closed by the pool by reason (max_idle, max_idle_time, max_lifetime)"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create database metric: %w", err)
+	}
+
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		s := db.Stats()
+		o.ObserveInt64(usage, int64(s.Idle), metric.WithAttributes(pool, attribute.String("state", "idle")))
+		o.ObserveInt64(usage, int64(s.InUse), metric.WithAttributes(pool, attribute.String("state", "used")))
+		o.ObserveInt64(maxConns, int64(s.MaxOpenConnections), metric.WithAttributes(pool))
+		o.ObserveInt64(waits, s.WaitCount, metric.WithAttributes(pool))
+		o.ObserveFloat64(waitTime, s.WaitDuration.Seconds(), metric.WithAttributes(pool))
+		o.ObserveInt64(closed, s.MaxIdleClosed, metric.WithAttributes(pool, attribute.String("reason", "max_idle")))
+		o.ObserveInt64(closed, s.MaxIdleTimeClosed, metric.WithAttributes(pool, attribute.String("reason", "max_idle_time")))
+		o.ObserveInt64(closed, s.MaxLifetimeClosed, metric.WithAttributes(pool, attribute.String("reason", "max_lifetime")))
+		return nil
+	}, usage, maxConns, waits, waitTime, closed)
+	if err != nil {
+		return fmt.Errorf("failed to register database metrics: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// migrationsTable - таблица с примененными версиями
+const migrationsTable = "schema_migrations"
+
+// migrationFile - имя файла миграции: <версия>_<описание>.sql, например 0001_create_users.sql
+var migrationFile = regexp.MustCompile(`^(\d+)_([A-Za-z0-9_\-]+)\.sql$`)
+
+// Migration - версионированный SQL-файл
+type Migration struct {
+	Version int64
+	Name    string
+	File    string
+}
+
+// Migrate применяет еще не примененные миграции из корня fsys по возрастанию версии
+// и возвращает примененные. Обычно вызывается из main при старте:
+//
+//	//go:embed migrations/*.sql
+//	var migrations embed.FS
+//	sub, _ := fs.Sub(migrations, "migrations")
+//	applied, err := database.Migrate(ctx, db, sub)
+//
+// Каждая миграция выполняется в своей транзакции вместе с записью в schema_migrations.
+// Файл может содержать несколько операторов: он выполняется одним Exec без параметров.
+// На время миграции берется advisory lock PostgreSQL: вторая реплика ждет,
+// пока первая закончит, и затем видит миграции уже примененными.
+func Migrate(ctx context.Context, db *sql.DB, fsys fs.FS) ([]Migration, error) {
+	migrations, err := readMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	// Advisory lock принадлежит сессии: lock, миграции и unlock идут через одно соединение
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection for migrations: %w", err)
+	}
+	defer conn.Close()
+
+	lockID := migrationLockID()
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockID); err != nil {
+		return nil, fmt.Errorf("failed to acquire migrations lock: %w", err)
+	}
+	defer func() {
+		// ctx мог быть отменен: lock все равно нужно отпустить, иначе он живет до закрытия соединения
+		_, _ = conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockID)
+	}()
+
+	if _, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+migrationsTable+` (
+		version    BIGINT PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`); err != nil {
+		return nil, fmt.Errorf("failed to create %s table: %w", migrationsTable, err)
+	}
+
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, m := range migrations {
+		if applied[m.Version] {
+			continue
+		}
+		if err := applyMigration(ctx, conn, fsys, m); err != nil {
+			return done, err
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// readMigrations читает список миграций; .sql с неверным именем и повтор версии - ошибка
+func readMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	var migrations []Migration
+	seen := make(map[int64]string)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		match := migrationFile.FindStringSubmatch(name)
+		if match == nil {
+			if strings.HasSuffix(name, ".sql") {
+				return nil, fmt.Errorf("invalid migration file name %s: want <version>_<name>.sql", name)
+			}
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", name, err)
+		}
+		if prev, ok := seen[version]; ok {
+			return nil, fmt.Errorf("duplicate migration version %d: %s, %s", version, prev, name)
+		}
+		seen[version] = name
+		migrations = append(migrations, Migration{Version: version, Name: match[2], File: name})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]bool, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version FROM "+migrationsTable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]bool)
+	for rows.Next() {
+		var version int64
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+func applyMigration(ctx context.Context, conn *sql.Conn, fsys fs.FS, m Migration) error {
+	body, err := fs.ReadFile(fsys, m.File)
+	if err != nil {
+		return fmt.Errorf("failed to read migration %s: %w", m.File, err)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %s: %w", m.File, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, string(body)); err != nil {
+		return fmt.Errorf("migration %s failed: %w", m.File, err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO "+migrationsTable+" (version, name) VALUES ($1, $2)", m.Version, m.Name,
+	); err != nil {
+		return fmt.Errorf("failed to record migration %s: %w", m.File, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %s: %w", m.File, err)
+	}
+	return nil
+}
+
+// migrationLockID - ключ advisory lock, общий для всех реплик (и сервисов) в одной БД
+func migrationLockID() int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(migrationsTable))
+	return int64(h.Sum64())
+}
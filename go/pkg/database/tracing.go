@@ -0,0 +1,353 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Обертка над драйвером database/sql: каждый Exec/Query (в том числе внутри транзакций
+// и через prepared statements) пишется в клиентский спан. Методы, которые драйвер
+// не реализует, возвращают driver.ErrSkip - database/sql сам откатывается на Prepare.
+
+// newConnector оборачивает коннектор зарегистрированного драйвера cfg.Driver
+func newConnector(cfg Config) (driver.Connector, error) {
+	// Экземпляр драйвера доступен только через sql.DB; соединений sql.Open не открывает
+	probe, err := sql.Open(cfg.Driver, "")
+	if err != nil {
+		return nil, fmt.Errorf("database driver %q is not registered (import it in main): %w", cfg.Driver, err)
+	}
+	drv := probe.Driver()
+	_ = probe.Close()
+
+	dsn := cfg.DSN()
+	var inner driver.Connector = dsnConnector{dsn: dsn, driver: drv}
+	if dc, ok := drv.(driver.DriverContext); ok {
+		if inner, err = dc.OpenConnector(dsn); err != nil {
+			return nil, fmt.Errorf("invalid database config: %w", err)
+		}
+	}
+
+	port, _ := strconv.Atoi(cfg.Port)
+	return &tracingConnector{
+		inner:  inner,
+		tracer: otel.Tracer("database"),
+		dbName: cfg.Database,
+		attrs: []attribute.KeyValue{
+			semconv.DBSystemPostgreSQL,
+			semconv.DBName(cfg.Database),
+			semconv.NetPeerName(cfg.Host),
+			semconv.NetPeerPort(port),
+		},
+	}, nil
+}
+
+// dsnConnector - коннектор для драйверов без driver.DriverContext
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) { return c.driver.Open(c.dsn) }
+func (c dsnConnector) Driver() driver.Driver                        { return c.driver }
+
+type tracingConnector struct {
+	inner  driver.Connector
+	tracer trace.Tracer
+	dbName string
+	attrs  []attribute.KeyValue
+}
+
+func (c *tracingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.inner.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &tracingConn{Conn: conn, c: c}, nil
+}
+
+func (c *tracingConnector) Driver() driver.Driver { return c.inner.Driver() }
+
+// startSpan открывает спан запроса. Имя - операция и БД ("SELECT orders"),
+// в db.statement попадает запрос с замаскированными литералами.
+func (c *tracingConnector) startSpan(ctx context.Context, query string) (context.Context, trace.Span) {
+	op := operation(query)
+	name := op
+	if c.dbName != "" {
+		name = op + " " + c.dbName
+	}
+
+	attrs := append([]attribute.KeyValue{
+		semconv.DBStatement(Redact(query)),
+		semconv.DBOperation(op),
+	}, c.attrs...)
+
+	return c.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// endSpan закрывает спан; driver.ErrSkip - не ошибка, а сигнал database/sql
+func endSpan(span trace.Span, err error) {
+	if err != nil && err != driver.ErrSkip {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+type tracingConn struct {
+	driver.Conn
+	c *tracingConnector
+}
+
+func (tc *tracingConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var (
+		stmt driver.Stmt
+		err  error
+	)
+	if p, ok := tc.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = p.PrepareContext(ctx, query)
+	} else {
+		stmt, err = tc.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tracingStmt{Stmt: stmt, query: query, c: tc.c}, nil
+}
+
+func (tc *tracingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := tc.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, span := tc.c.startSpan(ctx, query)
+	res, err := e.ExecContext(ctx, query, args)
+	endSpan(span, err)
+	return res, err
+}
+
+func (tc *tracingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := tc.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, span := tc.c.startSpan(ctx, query)
+	rows, err := q.QueryContext(ctx, query, args)
+	endSpan(span, err)
+	return rows, err
+}
+
+func (tc *tracingConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := tc.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	return tc.Conn.Begin() // Fallback для драйверов без ConnBeginTx
+}
+
+func (tc *tracingConn) Ping(ctx context.Context) error {
+	if p, ok := tc.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (tc *tracingConn) ResetSession(ctx context.Context) error {
+	if r, ok := tc.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (tc *tracingConn) IsValid() bool {
+	if v, ok := tc.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (tc *tracingConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if c, ok := tc.Conn.(driver.NamedValueChecker); ok {
+		return c.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+type tracingStmt struct {
+	driver.Stmt
+	query string
+	c     *tracingConnector
+}
+
+func (ts *tracingStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	ctx, span := ts.c.startSpan(ctx, ts.query)
+	var (
+		res driver.Result
+		err error
+	)
+	if e, ok := ts.Stmt.(driver.StmtExecContext); ok {
+		res, err = e.ExecContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedToValues(args); err == nil {
+			res, err = ts.Stmt.Exec(values) // Fallback для драйверов без StmtExecContext
+		}
+	}
+	endSpan(span, err)
+	return res, err
+}
+
+func (ts *tracingStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	ctx, span := ts.c.startSpan(ctx, ts.query)
+	var (
+		rows driver.Rows
+		err  error
+	)
+	if q, ok := ts.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = q.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedToValues(args); err == nil {
+			rows, err = ts.Stmt.Query(values) // Fallback для драйверов без StmtQueryContext
+		}
+	}
+	endSpan(span, err)
+	return rows, err
+}
+
+func (ts *tracingStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if c, ok := ts.Stmt.(driver.NamedValueChecker); ok {
+		return c.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+func namedToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, fmt.Errorf("database driver does not support named parameters")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
+
+// operation возвращает первое ключевое слово запроса (SELECT, INSERT, ...)
+func operation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "SQL"
+	}
+	return strings.ToUpper(fields[0])
+}
+
+// Redact заменяет литералы запроса на ?: строки ('...', $tag$...$tag$) и числа.
+// Плейсхолдеры ($1), идентификаторы ("col", t1) и ключевые слова остаются как есть.
+// Параметры запроса в спан не пишутся вовсе.
+func Redact(query string) string {
+	var b strings.Builder
+	b.Grow(len(query))
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'':
+			// '...' с экранированием удвоенной кавычкой
+			j := i + 1
+			for j < len(query) {
+				if query[j] == '\'' {
+					if j+1 < len(query) && query[j+1] == '\'' {
+						j += 2
+						continue
+					}
+					break
+				}
+				j++
+			}
+			b.WriteByte('?')
+			i = j
+
+		case c == '"':
+			// Идентификатор в кавычках копируется целиком
+			j := strings.IndexByte(query[i+1:], '"')
+			if j < 0 {
+				b.WriteString(query[i:])
+				return b.String()
+			}
+			b.WriteString(query[i : i+j+2])
+			i += j + 1
+
+		case c == '$':
+			if tag, ok := dollarTag(query[i:]); ok {
+				end := strings.Index(query[i+len(tag):], tag)
+				b.WriteByte('?')
+				if end < 0 {
+					return b.String()
+				}
+				i += len(tag) + end + len(tag) - 1
+				continue
+			}
+			// Плейсхолдер $1
+			j := i + 1
+			for j < len(query) && isDigit(query[j]) {
+				j++
+			}
+			b.WriteString(query[i:j])
+			i = j - 1
+
+		case isDigit(c) && (i == 0 || !isIdentChar(query[i-1])):
+			j := i
+			for j < len(query) && (isDigit(query[j]) || query[j] == '.') {
+				j++
+			}
+			b.WriteByte('?')
+			i = j - 1
+
+		case isIdentChar(c):
+			// Идентификатор целиком, чтобы цифры в t1 не приняли за число
+			j := i
+			for j < len(query) && isIdentChar(query[j]) {
+				j++
+			}
+			b.WriteString(query[i:j])
+			i = j - 1
+
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// dollarTag распознает начало строки в долларовых кавычках: $$ или $tag$
+func dollarTag(s string) (string, bool) {
+	for j := 1; j < len(s); j++ {
+		switch {
+		case s[j] == '$':
+			return s[:j+1], true
+		case isDigit(s[j]) && j == 1:
+			return "", false // $1 - плейсхолдер
+		case !isIdentChar(s[j]):
+			return "", false
+		}
+	}
+	return "", false
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func isIdentChar(c byte) bool {
+	return c == '_' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
@@ -0,0 +1,128 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"string literal", "SELECT * FROM users WHERE email = 'a@b.c'", "SELECT * FROM users WHERE email = ?"},
+		{"escaped quote", "UPDATE t SET name = 'O''Brien' WHERE id = 1", "UPDATE t SET name = ? WHERE id = ?"},
+		{"numbers", "SELECT * FROM t LIMIT 10 OFFSET 2.5", "SELECT * FROM t LIMIT ? OFFSET ?"},
+		{"placeholders kept", "INSERT INTO t (a, b) VALUES ($1, $2)", "INSERT INTO t (a, b) VALUES ($1, $2)"},
+		{"identifiers with digits", "SELECT t1.col2 FROM table3 t1", "SELECT t1.col2 FROM table3 t1"},
+		{"quoted identifier", `SELECT "user 1" FROM t WHERE x = 'y'`, `SELECT "user 1" FROM t WHERE x = ?`},
+		{"dollar quoted", "SELECT $$secret$$, $tag$with 'quote'$tag$, $1", "SELECT ?, ?, $1"},
+		{"unterminated dollar quote", "SELECT $$secret", "SELECT ?"},
+		{"password in DDL", "ALTER USER app WITH PASSWORD 'hunter2'", "ALTER USER app WITH PASSWORD ?"},
+		{"unicode identifier", "SELECT имя FROM t WHERE n = 5", "SELECT имя FROM t WHERE n = ?"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Redact(tt.query); got != tt.want {
+				t.Errorf("Redact(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeDriver - драйвер без сети: Exec и Query ничего не делают
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return fakeStmt{}, nil }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return fakeTx{}, nil }
+
+type fakeStmt struct{}
+
+func (fakeStmt) Close() error                               { return nil }
+func (fakeStmt) NumInput() int                              { return -1 }
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) { return driver.RowsAffected(1), nil }
+func (fakeStmt) Query([]driver.Value) (driver.Rows, error)  { return fakeRows{}, nil }
+
+type fakeRows struct{}
+
+func (fakeRows) Columns() []string         { return []string{"id"} }
+func (fakeRows) Close() error              { return nil }
+func (fakeRows) Next([]driver.Value) error { return io.EOF }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+func init() {
+	sql.Register("fake-tracing", fakeDriver{})
+}
+
+func TestTracingSpanStatement(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	connector, err := newConnector(Config{Driver: "fake-tracing", Host: "db", Port: "5432", User: "app", Password: "p@ss", Database: "chat"})
+	if err != nil {
+		t.Fatalf("newConnector: %v", err)
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	ctx := context.Background()
+	if _, err := db.ExecContext(ctx, "UPDATE users SET password = 'hunter2' WHERE id = $1", 42); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.QueryContext(ctx, "SELECT id FROM users WHERE email = 'a@b.c'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = rows.Close()
+
+	want := map[string]string{
+		"UPDATE chat": "UPDATE users SET password = ? WHERE id = $1",
+		"SELECT chat": "SELECT id FROM users WHERE email = ?",
+	}
+	found := 0
+	for _, span := range rec.Ended() {
+		stmt, ok := want[span.Name()]
+		if !ok {
+			continue
+		}
+		found++
+		attrs := make(map[string]string)
+		for _, kv := range span.Attributes() {
+			attrs[string(kv.Key)] = kv.Value.Emit()
+		}
+		if attrs["db.statement"] != stmt {
+			t.Errorf("%s: db.statement = %q, want %q", span.Name(), attrs["db.statement"], stmt)
+		}
+		if attrs["db.name"] != "chat" || attrs["net.peer.name"] != "db" {
+			t.Errorf("%s: attributes = %v", span.Name(), attrs)
+		}
+		for _, v := range attrs {
+			if v == "hunter2" || v == "42" || v == "p@ss" {
+				t.Errorf("%s: sensitive value in attributes: %v", span.Name(), attrs)
+			}
+		}
+	}
+	if found != len(want) {
+		t.Errorf("found %d query spans, want %d", found, len(want))
+	}
+}
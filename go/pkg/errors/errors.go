@@ -1,9 +1,149 @@
+// Package errors - типизированные ошибки сервисов: код, сообщение для клиента, детали, признак повтора и причина.
+// HandlerFunc отдает их по HTTP как application/problem+json (RFC 7807), UnaryServerInterceptor - как gRPC status
+// с errdetails. В обоих ответах есть trace_id. Сервисы импортируют пакет как apperrors, чтобы не перекрывать stdlib.
 package errors
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Code - класс ошибки, определяет HTTP-статус и gRPC-код
+type Code string
+
+const (
+	CodeInvalidArgument   Code = "invalid_argument"   // 400, InvalidArgument
+	CodeUnauthenticated   Code = "unauthenticated"    // 401, Unauthenticated
+	CodePermissionDenied  Code = "permission_denied"  // 403, PermissionDenied
+	CodeNotFound          Code = "not_found"          // 404, NotFound
+	CodeAlreadyExists     Code = "already_exists"     // 409, AlreadyExists
+	CodeResourceExhausted Code = "resource_exhausted" // 429, ResourceExhausted
+	CodeCanceled          Code = "canceled"           // 499, Canceled
+	CodeInternal          Code = "internal"           // 500, Internal
+	CodeUnavailable       Code = "unavailable"        // 503, Unavailable
+	CodeDeadlineExceeded  Code = "deadline_exceeded"  // 504, DeadlineExceeded
+)
+
+// retryDelay - через сколько советовать повтор (Retry-After, errdetails.RetryInfo)
+const retryDelay = time.Second
 
+// Error - ошибка с кодом. Message уходит клиенту как есть, Cause - только в логи.
+type Error struct {
+	Code      Code
+	Message   string
+	Details   map[string]string // Для invalid_argument - поле -> описание нарушения
+	Retryable bool              // Запрос можно повторить без изменений
+	Cause     error
+}
+
+// Sentinel-ошибки: errors.Is(err, ErrNotFound) истинно для любой *Error с тем же кодом.
+// Общие на процесс - WithDetail/WithRetryable вызывают на New(...), а не на них.
 var (
-	ErrNotFound      = errors.New("not found")
-	ErrUnauthorized  = errors.New("unauthorized")
-	ErrBadRequest    = errors.New("bad request")
+	ErrNotFound     = New(CodeNotFound, "not found")
+	ErrUnauthorized = New(CodeUnauthenticated, "unauthorized")
+	ErrBadRequest   = New(CodeInvalidArgument, "bad request")
 )
+
+// New создает ошибку; unavailable, deadline_exceeded и resource_exhausted по умолчанию повторяемые
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message, Retryable: retryableByDefault(code)}
+}
+
+// Wrap создает ошибку с причиной cause
+func Wrap(cause error, code Code, message string) *Error {
+	e := New(code, message)
+	e.Cause = cause
+	return e
+}
+
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Cause)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Is сравнивает по коду: errors.Is(err, ErrNotFound)
+func (e *Error) Is(target error) bool {
+	var t *Error
+	return errors.As(target, &t) && t.Code == e.Code
+}
+
+// WithDetail добавляет деталь (для invalid_argument - нарушение в поле key)
+func (e *Error) WithDetail(key, value string) *Error {
+	if e.Details == nil {
+		e.Details = make(map[string]string)
+	}
+	e.Details[key] = value
+	return e
+}
+
+// WithRetryable переопределяет признак повтора, заданный кодом
+func (e *Error) WithRetryable(retryable bool) *Error {
+	e.Retryable = retryable
+	return e
+}
+
+// Rule - правило классификации для Classify
+type Rule struct {
+	code    Code
+	targets []error
+}
+
+// On относит к коду code ошибки, для которых errors.Is(err, target) истинно хотя бы для одного target
+func On(code Code, targets ...error) Rule {
+	return Rule{code: code, targets: targets}
+}
+
+// Classify переводит доменную ошибку в *Error по первому подходящему правилу; сообщением становится
+// текст sentinel-ошибки домена (без обертки "domain error: ..."). Без совпадений err возвращается как есть:
+// HandlerFunc и UnaryServerInterceptor отдадут его как internal.
+//
+//	return nil, apperrors.Classify(err, apperrors.On(apperrors.CodeInvalidArgument, domain.ErrEmptyName))
+func Classify(err error, rules ...Rule) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+	for _, rule := range rules {
+		for _, target := range rule.targets {
+			if errors.Is(err, target) {
+				return Wrap(err, rule.code, target.Error())
+			}
+		}
+	}
+	return err
+}
+
+// From возвращает *Error из цепочки err; отмена и таймаут контекста получают свои коды,
+// остальное - internal с общим сообщением (текст причины клиенту не отдается).
+func From(err error) *Error {
+	var e *Error
+	switch {
+	case errors.As(err, &e):
+		return e
+	case errors.Is(err, context.Canceled):
+		return Wrap(err, CodeCanceled, "request canceled")
+	case errors.Is(err, context.DeadlineExceeded):
+		return Wrap(err, CodeDeadlineExceeded, "deadline exceeded")
+	default:
+		return Wrap(err, CodeInternal, "internal error")
+	}
+}
+
+func retryableByDefault(code Code) bool {
+	switch code {
+	case CodeUnavailable, CodeDeadlineExceeded, CodeResourceExhausted:
+		return true
+	}
+	return false
+}
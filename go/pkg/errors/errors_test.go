@@ -0,0 +1,133 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+)
+
+// errEmptyName повторяет sentinel-ошибку домена (domain.ErrEmptyName сервисов)
+var errEmptyName = errors.New("name cannot be empty")
+
+func TestCodeMapping(t *testing.T) {
+	tests := []struct {
+		code      Code
+		http      int
+		grpc      codes.Code
+		retryable bool
+	}{
+		{CodeInvalidArgument, http.StatusBadRequest, codes.InvalidArgument, false},
+		{CodeUnauthenticated, http.StatusUnauthorized, codes.Unauthenticated, false},
+		{CodePermissionDenied, http.StatusForbidden, codes.PermissionDenied, false},
+		{CodeNotFound, http.StatusNotFound, codes.NotFound, false},
+		{CodeAlreadyExists, http.StatusConflict, codes.AlreadyExists, false},
+		{CodeResourceExhausted, http.StatusTooManyRequests, codes.ResourceExhausted, true},
+		{CodeCanceled, 499, codes.Canceled, false},
+		{CodeInternal, http.StatusInternalServerError, codes.Internal, false},
+		{CodeUnavailable, http.StatusServiceUnavailable, codes.Unavailable, true},
+		{CodeDeadlineExceeded, http.StatusGatewayTimeout, codes.DeadlineExceeded, true},
+		{Code("teapot"), http.StatusInternalServerError, codes.Internal, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.code), func(t *testing.T) {
+			if got := HTTPStatus(tt.code); got != tt.http {
+				t.Errorf("HTTPStatus = %d, want %d", got, tt.http)
+			}
+			if got := GRPCCode(tt.code); got != tt.grpc {
+				t.Errorf("GRPCCode = %v, want %v", got, tt.grpc)
+			}
+			if got := New(tt.code, "msg").Retryable; got != tt.retryable {
+				t.Errorf("Retryable = %t, want %t", got, tt.retryable)
+			}
+		})
+	}
+}
+
+func TestFrom(t *testing.T) {
+	typed := New(CodeNotFound, "user not found")
+
+	tests := []struct {
+		name    string
+		err     error
+		code    Code
+		message string
+	}{
+		{"typed", typed, CodeNotFound, "user not found"},
+		{"wrapped typed", fmt.Errorf("repo: %w", typed), CodeNotFound, "user not found"},
+		{"canceled", fmt.Errorf("query: %w", context.Canceled), CodeCanceled, "request canceled"},
+		{"deadline", context.DeadlineExceeded, CodeDeadlineExceeded, "deadline exceeded"},
+		{"unclassified hides cause", errors.New("pq: password authentication failed"), CodeInternal, "internal error"},
+		{"unclassified domain error", errEmptyName, CodeInternal, "internal error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := From(tt.err)
+			if e.Code != tt.code || e.Message != tt.message {
+				t.Errorf("From = %s %q, want %s %q", e.Code, e.Message, tt.code, tt.message)
+			}
+		})
+	}
+}
+
+func TestClassify(t *testing.T) {
+	errUnavailable := errors.New("broker unavailable")
+	rules := []Rule{
+		On(CodeInvalidArgument, errEmptyName),
+		On(CodeUnavailable, errUnavailable),
+	}
+
+	tests := []struct {
+		name    string
+		err     error
+		code    Code
+		message string
+		http    int
+		grpc    codes.Code
+	}{
+		{"sentinel", errEmptyName, CodeInvalidArgument, "name cannot be empty", http.StatusBadRequest, codes.InvalidArgument},
+		{"wrapped sentinel", fmt.Errorf("domain error: %w", errEmptyName), CodeInvalidArgument, "name cannot be empty", http.StatusBadRequest, codes.InvalidArgument},
+		{"second rule", fmt.Errorf("publish: %w", errUnavailable), CodeUnavailable, "broker unavailable", http.StatusServiceUnavailable, codes.Unavailable},
+		{"typed kept", New(CodeNotFound, "missing"), CodeNotFound, "missing", http.StatusNotFound, codes.NotFound},
+		{"no match", errors.New("boom"), CodeInternal, "internal error", http.StatusInternalServerError, codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Classify(tt.err, rules...)
+			e := From(err)
+			if e.Code != tt.code || e.Message != tt.message {
+				t.Errorf("classified as %s %q, want %s %q", e.Code, e.Message, tt.code, tt.message)
+			}
+			if got := HTTPStatus(e.Code); got != tt.http {
+				t.Errorf("HTTP status = %d, want %d", got, tt.http)
+			}
+			if got := Status(context.Background(), err).Code(); got != tt.grpc {
+				t.Errorf("gRPC code = %v, want %v", got, tt.grpc)
+			}
+			// Причина сохраняется для логов и errors.Is
+			if !errors.Is(err, tt.err) {
+				t.Errorf("errors.Is(classified, original) = false")
+			}
+		})
+	}
+
+	if Classify(nil, rules...) != nil {
+		t.Error("Classify(nil) != nil")
+	}
+}
+
+func TestIsByCode(t *testing.T) {
+	err := fmt.Errorf("lookup: %w", New(CodeNotFound, "user 42 not found"))
+	if !errors.Is(err, ErrNotFound) {
+		t.Error("errors.Is(err, ErrNotFound) = false for not_found error")
+	}
+	if errors.Is(err, ErrBadRequest) {
+		t.Error("errors.Is(err, ErrBadRequest) = true for not_found error")
+	}
+}
@@ -0,0 +1,92 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+var grpcCode = map[Code]codes.Code{
+	CodeInvalidArgument:   codes.InvalidArgument,
+	CodeUnauthenticated:   codes.Unauthenticated,
+	CodePermissionDenied:  codes.PermissionDenied,
+	CodeNotFound:          codes.NotFound,
+	CodeAlreadyExists:     codes.AlreadyExists,
+	CodeResourceExhausted: codes.ResourceExhausted,
+	CodeCanceled:          codes.Canceled,
+	CodeInternal:          codes.Internal,
+	CodeUnavailable:       codes.Unavailable,
+	CodeDeadlineExceeded:  codes.DeadlineExceeded,
+}
+
+// GRPCCode - gRPC-код для кода ошибки; неизвестный код - Internal
+func GRPCCode(code Code) codes.Code {
+	if c, ok := grpcCode[code]; ok {
+		return c
+	}
+	return codes.Internal
+}
+
+// Status переводит err в gRPC status (см. From) с errdetails:
+// ErrorInfo (reason - код, metadata - детали), BadRequest для invalid_argument, RetryInfo для повторяемых
+// и RequestInfo с trace_id в request_id.
+func Status(ctx context.Context, err error) *status.Status {
+	e := From(err)
+	st := status.New(GRPCCode(e.Code), e.Message)
+
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{
+		Reason:   strings.ToUpper(string(e.Code)),
+		Metadata: e.Details,
+	}}
+	if e.Code == CodeInvalidArgument && len(e.Details) > 0 {
+		violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(e.Details))
+		for _, field := range slices.Sorted(maps.Keys(e.Details)) {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: field, Description: e.Details[field]})
+		}
+		details = append(details, &errdetails.BadRequest{FieldViolations: violations})
+	}
+	if e.Retryable {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(retryDelay)})
+	}
+	if id := traceID(ctx); id != "" {
+		details = append(details, &errdetails.RequestInfo{RequestId: id})
+	}
+
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		return withDetails
+	}
+	return st
+}
+
+// UnaryServerInterceptor переводит ошибки обработчиков в gRPC status (см. Status). Ошибки, уже являющиеся
+// status (status.Error в обработчике), передаются как есть. Ставится последним в grpc.ChainUnaryInterceptor.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+
+		var e *Error
+		if _, ok := status.FromError(err); ok && !errors.As(err, &e) {
+			return resp, err
+		}
+
+		st := Status(ctx, err)
+		if HTTPStatus(From(err).Code) >= http.StatusInternalServerError {
+			slog.ErrorContext(ctx, "❌ RPC failed", "code", st.Code().String(), "error", err)
+		}
+		return resp, st.Err()
+	}
+}
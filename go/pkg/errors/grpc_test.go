@@ -0,0 +1,89 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStatusDetails(t *testing.T) {
+	err := New(CodeInvalidArgument, "invalid request").WithDetail("name", "must not be empty")
+	st := Status(context.Background(), err)
+
+	if st.Code() != codes.InvalidArgument || st.Message() != "invalid request" {
+		t.Fatalf("status = %v %q", st.Code(), st.Message())
+	}
+
+	var info *errdetails.ErrorInfo
+	var badRequest *errdetails.BadRequest
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			info = d
+		case *errdetails.BadRequest:
+			badRequest = d
+		case *errdetails.RetryInfo:
+			t.Error("RetryInfo for non-retryable error")
+		}
+	}
+	if info == nil || info.Reason != "INVALID_ARGUMENT" || info.Metadata["name"] != "must not be empty" {
+		t.Errorf("ErrorInfo = %v", info)
+	}
+	if badRequest == nil || len(badRequest.FieldViolations) != 1 || badRequest.FieldViolations[0].Field != "name" {
+		t.Errorf("BadRequest = %v", badRequest)
+	}
+}
+
+func TestStatusRetryInfo(t *testing.T) {
+	st := Status(context.Background(), New(CodeUnavailable, "try later"))
+	for _, d := range st.Details() {
+		if ri, ok := d.(*errdetails.RetryInfo); ok {
+			if ri.RetryDelay.AsDuration() != retryDelay {
+				t.Errorf("RetryDelay = %v, want %v", ri.RetryDelay.AsDuration(), retryDelay)
+			}
+			return
+		}
+	}
+	t.Error("no RetryInfo for retryable error")
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code codes.Code
+		msg  string
+	}{
+		{"typed", New(CodeNotFound, "user not found"), codes.NotFound, "user not found"},
+		{"classified domain error", Classify(errEmptyName, On(CodeInvalidArgument, errEmptyName)), codes.InvalidArgument, "name cannot be empty"},
+		{"status passes through", status.Error(codes.Aborted, "conflict"), codes.Aborted, "conflict"},
+		{"plain error is internal", errors.New("boom"), codes.Internal, "internal error"},
+		{"context deadline", context.DeadlineExceeded, codes.DeadlineExceeded, "deadline exceeded"},
+	}
+
+	interceptor := UnaryServerInterceptor()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := func(context.Context, any) (any, error) { return nil, tt.err }
+			_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/test/Call"}, handler)
+
+			st, ok := status.FromError(err)
+			if !ok {
+				t.Fatalf("error %v is not a gRPC status", err)
+			}
+			if st.Code() != tt.code || st.Message() != tt.msg {
+				t.Errorf("status = %v %q, want %v %q", st.Code(), st.Message(), tt.code, tt.msg)
+			}
+		})
+	}
+
+	resp, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, func(context.Context, any) (any, error) { return "ok", nil })
+	if err != nil || resp != "ok" {
+		t.Errorf("success = %v, %v", resp, err)
+	}
+}
@@ -0,0 +1,102 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ContentTypeProblem - тип ответа с ошибкой (RFC 7807)
+const ContentTypeProblem = "application/problem+json"
+
+// Problem - тело ответа RFC 7807; code, retryable, trace_id и errors - расширения
+type Problem struct {
+	Type      string            `json:"type"`
+	Title     string            `json:"title"`
+	Status    int               `json:"status"`
+	Detail    string            `json:"detail,omitempty"`
+	Instance  string            `json:"instance,omitempty"`
+	Code      Code              `json:"code"`
+	Retryable bool              `json:"retryable"`
+	TraceID   string            `json:"trace_id,omitempty"`
+	Errors    map[string]string `json:"errors,omitempty"`
+}
+
+var httpStatus = map[Code]int{
+	CodeInvalidArgument:   http.StatusBadRequest,
+	CodeUnauthenticated:   http.StatusUnauthorized,
+	CodePermissionDenied:  http.StatusForbidden,
+	CodeNotFound:          http.StatusNotFound,
+	CodeAlreadyExists:     http.StatusConflict,
+	CodeResourceExhausted: http.StatusTooManyRequests,
+	CodeCanceled:          499, // Client Closed Request (nginx)
+	CodeInternal:          http.StatusInternalServerError,
+	CodeUnavailable:       http.StatusServiceUnavailable,
+	CodeDeadlineExceeded:  http.StatusGatewayTimeout,
+}
+
+// HTTPStatus - HTTP-статус для кода; неизвестный код - 500
+func HTTPStatus(code Code) int {
+	if status, ok := httpStatus[code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// HandlerFunc - HTTP-обработчик, возвращающий ошибку; ошибка отдается через WriteProblem.
+// Ответ к моменту возврата ошибки не должен быть начат.
+//
+//	mux.Handle("/hello", otelhttp.NewHandler(apperrors.HandlerFunc(s.HandleGreet), "HTTP /hello"))
+type HandlerFunc func(http.ResponseWriter, *http.Request) error
+
+func (f HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := f(w, r); err != nil {
+		WriteProblem(w, r, err)
+	}
+}
+
+// WriteProblem отдает err как application/problem+json (см. From). Ошибки 5xx пишутся в лог с причиной,
+// у повторяемых выставляется Retry-After.
+func WriteProblem(w http.ResponseWriter, r *http.Request, err error) {
+	e := From(err)
+	status := HTTPStatus(e.Code)
+
+	if status >= http.StatusInternalServerError {
+		slog.ErrorContext(r.Context(), "❌ Request failed", "code", e.Code, "error", err)
+	}
+
+	problem := Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    e.Message,
+		Instance:  r.URL.Path,
+		Code:      e.Code,
+		Retryable: e.Retryable,
+		TraceID:   traceID(r.Context()),
+		Errors:    e.Details,
+	}
+	if problem.Title == "" {
+		problem.Title = string(e.Code)
+	}
+
+	w.Header().Set("Content-Type", ContentTypeProblem)
+	if e.Retryable {
+		w.Header().Set("Retry-After", strconv.Itoa(int(retryDelay.Seconds())))
+	}
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(problem)
+}
+
+// traceID - trace_id текущего спана для ответа клиенту; пусто, если трейса нет
+func traceID(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.HasTraceID() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
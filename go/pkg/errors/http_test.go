@@ -0,0 +1,83 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandlerFuncWritesProblem(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		status     int
+		detail     string
+		retryAfter string
+		errs       map[string]string
+	}{
+		{
+			name:   "invalid argument with details",
+			err:    New(CodeInvalidArgument, "invalid request").WithDetail("name", "must not be empty"),
+			status: http.StatusBadRequest,
+			detail: "invalid request",
+			errs:   map[string]string{"name": "must not be empty"},
+		},
+		{
+			name:       "retryable",
+			err:        Wrap(errors.New("kafka: no brokers"), CodeUnavailable, "try later"),
+			status:     http.StatusServiceUnavailable,
+			detail:     "try later",
+			retryAfter: "1",
+		},
+		{
+			name:   "internal hides cause",
+			err:    fmt.Errorf("db: %w", errors.New("connection refused")),
+			status: http.StatusInternalServerError,
+			detail: "internal error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := HandlerFunc(func(http.ResponseWriter, *http.Request) error { return tt.err })
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/hello", nil))
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if got := rec.Header().Get("Content-Type"); got != ContentTypeProblem {
+				t.Errorf("Content-Type = %q", got)
+			}
+			if got := rec.Header().Get("Retry-After"); got != tt.retryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.retryAfter)
+			}
+
+			var p Problem
+			if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+				t.Fatal(err)
+			}
+			if p.Status != tt.status || p.Detail != tt.detail || p.Instance != "/hello" || p.Title != http.StatusText(tt.status) {
+				t.Errorf("problem = %+v", p)
+			}
+			if len(p.Errors) != len(tt.errs) || p.Errors["name"] != tt.errs["name"] {
+				t.Errorf("errors = %v, want %v", p.Errors, tt.errs)
+			}
+		})
+	}
+}
+
+func TestHandlerFuncSuccess(t *testing.T) {
+	h := HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("status = %d, want 204", rec.Code)
+	}
+}
@@ -0,0 +1,333 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"go.yaml.in/yaml/v3"
+)
+
+// Причины, по которым пользователь получил вариант (атрибут метрики и ответ admin-эндпоинта)
+const (
+	ReasonUnknown = "unknown" // флаг не объявлен в файле
+	ReasonDefault = "default" // ни одно правило не сработало
+	ReasonDeny    = "deny"    // пользователь в deny: всегда default, вне раскатки
+	ReasonTarget  = "target"  // пользователь в allow/targets
+	ReasonRollout = "rollout" // попал в процент раскатки
+)
+
+// Варианты булевого флага (variants не заданы)
+var boolVariants = []string{"false", "true"}
+
+// Rules - правила вычисления флага. Могут переопределяться для окружения (Flag.Environments).
+type Rules struct {
+	// Default - вариант, если ни одно правило не сработало
+	Default *string `yaml:"default" json:"default,omitempty"`
+	// Rollout - процент пользователей на вариант: {"true": 10} или {"a": 25, "b": 25}.
+	// Остальные получают Default. Пользователь стабильно попадает в один бакет (хеш ключа флага и userID).
+	Rollout map[string]float64 `yaml:"rollout" json:"rollout,omitempty"`
+	// Allow - пользователи, всегда получающие "true" (только для булевых флагов)
+	Allow []string `yaml:"allow" json:"allow,omitempty"`
+	// Deny - пользователи, всегда получающие Default; проверяется первым
+	Deny []string `yaml:"deny" json:"deny,omitempty"`
+	// Targets - явное назначение варианта пользователям: {"video": ["qa-1"]}
+	Targets map[string][]string `yaml:"targets" json:"targets,omitempty"`
+}
+
+// Flag - описание флага в файле
+type Flag struct {
+	Description string `yaml:"description" json:"description,omitempty"`
+	// Variants - варианты мультивариантного флага. Если не заданы, флаг булев: "false"/"true".
+	Variants []string `yaml:"variants" json:"variants,omitempty"`
+
+	Rules `yaml:",inline" json:"rules"`
+
+	// Environments - переопределения правил по APP_ENV: заданные поля заменяют базовые целиком
+	Environments map[string]Rules `yaml:"environments" json:"environments,omitempty"`
+}
+
+// file - формат файла флагов (configs/flags.yaml)
+type file struct {
+	Flags map[string]Flag `yaml:"flags"`
+}
+
+// Evaluation - результат вычисления флага для пользователя
+type Evaluation struct {
+	Key     string `json:"key"`
+	Variant string `json:"variant"`
+	Reason  string `json:"reason"`
+}
+
+// Client вычисляет флаги из файла. Безопасен для конкурентного использования.
+type Client struct {
+	path string
+	env  string
+
+	mu    sync.RWMutex
+	flags map[string]Flag
+
+	evaluations metric.Int64Counter
+
+	// Состояние Watch (см. watch.go)
+	stop func()
+}
+
+// New читает файл флагов для окружения env. Отсутствующий файл - не ошибка:
+// все флаги вычисляются в default (false для булевых), пока файл не появится (Watch).
+func New(path, env string) (*Client, error) {
+	evaluations, err := otel.Meter("flags").Int64Counter("feature_flag.evaluations",
+		metric.WithDescription("Feature flag evaluations by flag, variant and reason"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create flags metric: %w", err)
+	}
+
+	c := &Client{
+		path:        path,
+		env:         env,
+		flags:       make(map[string]Flag),
+		evaluations: evaluations,
+	}
+
+	flags, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if flags != nil {
+		c.flags = flags
+		slog.Default().Info("✅ Loaded feature flags", "path", path)
+	}
+	return c, nil
+}
+
+// readFile читает и валидирует файл. Для отсутствующего файла возвращает nil, nil.
+func readFile(path string) (map[string]Flag, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read flags file: %w", err)
+	}
+
+	var f file
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse flags file %s: %w", path, err)
+	}
+	if f.Flags == nil {
+		f.Flags = make(map[string]Flag)
+	}
+
+	var errs []error
+	for _, key := range sortedKeys(f.Flags) {
+		if err := f.Flags[key].validate(); err != nil {
+			errs = append(errs, fmt.Errorf("flag %q: %w", key, err))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid flags file %s: %w", path, errors.Join(errs...))
+	}
+	return f.Flags, nil
+}
+
+func (f Flag) variants() []string {
+	if len(f.Variants) == 0 {
+		return boolVariants
+	}
+	return f.Variants
+}
+
+func (f Flag) isBool() bool {
+	return len(f.Variants) == 0
+}
+
+// rules возвращает правила с учетом переопределений окружения
+func (f Flag) rules(env string) Rules {
+	r := f.Rules
+	override, ok := f.Environments[env]
+	if !ok {
+		return r
+	}
+	if override.Default != nil {
+		r.Default = override.Default
+	}
+	if override.Rollout != nil {
+		r.Rollout = override.Rollout
+	}
+	if override.Allow != nil {
+		r.Allow = override.Allow
+	}
+	if override.Deny != nil {
+		r.Deny = override.Deny
+	}
+	if override.Targets != nil {
+		r.Targets = override.Targets
+	}
+	return r
+}
+
+func (f Flag) defaultVariant(r Rules) string {
+	if r.Default != nil {
+		return *r.Default
+	}
+	return f.variants()[0]
+}
+
+func (f Flag) validate() error {
+	known := make(map[string]bool)
+	for _, v := range f.variants() {
+		if known[v] {
+			return fmt.Errorf("duplicate variant %q", v)
+		}
+		known[v] = true
+	}
+
+	check := func(scope string, r Rules) error {
+		if r.Default != nil && !known[*r.Default] {
+			return fmt.Errorf("%sdefault: unknown variant %q", scope, *r.Default)
+		}
+		total := 0.0
+		for v, pct := range r.Rollout {
+			if !known[v] {
+				return fmt.Errorf("%srollout: unknown variant %q", scope, v)
+			}
+			if pct < 0 {
+				return fmt.Errorf("%srollout: negative percentage for %q", scope, v)
+			}
+			total += pct
+		}
+		if total > 100 {
+			return fmt.Errorf("%srollout: percentages sum to %.2f, want <= 100", scope, total)
+		}
+		if len(r.Allow) > 0 && !f.isBool() {
+			return fmt.Errorf("%sallow is only supported for boolean flags, use targets", scope)
+		}
+		for v := range r.Targets {
+			if !known[v] {
+				return fmt.Errorf("%stargets: unknown variant %q", scope, v)
+			}
+		}
+		return nil
+	}
+
+	if err := check("", f.Rules); err != nil {
+		return err
+	}
+	for env, r := range f.Environments {
+		if err := check("environments."+env+".", r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Bool вычисляет булев флаг. Неизвестный флаг - false.
+func (c *Client) Bool(ctx context.Context, key, userID string) bool {
+	return c.Evaluate(ctx, key, userID).Variant == "true"
+}
+
+// Variant вычисляет мультивариантный флаг. Неизвестный флаг - пустая строка.
+func (c *Client) Variant(ctx context.Context, key, userID string) string {
+	return c.Evaluate(ctx, key, userID).Variant
+}
+
+// Evaluate вычисляет флаг для пользователя и записывает результат
+// в атрибут текущего спана (feature_flag.<key>) и метрику feature_flag.evaluations.
+// Пустой userID (аноним) получает default: allow/targets/rollout к нему не применяются.
+func (c *Client) Evaluate(ctx context.Context, key, userID string) Evaluation {
+	ev := c.evaluate(key, userID)
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		attribute.String("feature_flag."+key, ev.Variant),
+		attribute.String("feature_flag."+key+".reason", ev.Reason),
+	)
+
+	c.evaluations.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("feature_flag.key", ev.Key),
+		attribute.String("feature_flag.variant", ev.Variant),
+		attribute.String("feature_flag.reason", ev.Reason),
+	))
+
+	return ev
+}
+
+// evaluate - вычисление без телеметрии (используется и admin-эндпоинтом)
+func (c *Client) evaluate(key, userID string) Evaluation {
+	c.mu.RLock()
+	f, ok := c.flags[key]
+	c.mu.RUnlock()
+
+	if !ok {
+		return Evaluation{Key: key, Reason: ReasonUnknown}
+	}
+
+	r := f.rules(c.env)
+	ev := Evaluation{Key: key, Variant: f.defaultVariant(r), Reason: ReasonDefault}
+	if userID == "" {
+		return ev
+	}
+
+	if contains(r.Deny, userID) {
+		ev.Reason = ReasonDeny
+		return ev
+	}
+	if f.isBool() && contains(r.Allow, userID) {
+		ev.Variant, ev.Reason = "true", ReasonTarget
+		return ev
+	}
+	for _, v := range f.variants() {
+		if contains(r.Targets[v], userID) {
+			ev.Variant, ev.Reason = v, ReasonTarget
+			return ev
+		}
+	}
+
+	// Бакет 0..9999: проценты с точностью до сотых. Диапазоны вариантов идут подряд от нуля,
+	// поэтому у булевого флага рост процента только добавляет пользователей в раскатку.
+	b := bucket(key, userID)
+	upper := 0.0
+	for _, v := range f.variants() {
+		upper += r.Rollout[v] * 100
+		if float64(b) < upper {
+			ev.Variant, ev.Reason = v, ReasonRollout
+			return ev
+		}
+	}
+	return ev
+}
+
+// bucket стабильно отображает пользователя в 0..9999 для конкретного флага
+func bucket(key, userID string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key + "/" + userID))
+	return h.Sum32() % 10000
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]Flag) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,192 @@
+package flags
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testFlags = `
+flags:
+  new-ui:
+    description: Новый интерфейс
+    rollout: {"true": 20}
+    allow: [qa-1, blocked-1]
+    deny: [blocked-1, blocked-2]
+    targets: {"true": [blocked-2]}
+    environments:
+      dev:
+        default: "true"
+      staging:
+        rollout: {"true": 100}
+        deny: []
+  theme:
+    variants: [classic, dark, neon]
+    default: classic
+    rollout: {dark: 30, neon: 20}
+    targets: {neon: [designer-1]}
+  off:
+    description: Флаг без правил
+`
+
+// newClient создает клиент из содержимого файла флагов
+func newClient(t *testing.T, content, env string) *Client {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "flags.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := New(path, env)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return c
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		env     string
+		key     string
+		user    string
+		variant string
+		reason  string
+	}{
+		// Раскатка 20%: бакеты user-1 = 1474, user-2 = 3855 (см. TestBucketStable)
+		{"prod", "new-ui", "user-1", "true", ReasonRollout},
+		{"prod", "new-ui", "user-2", "false", ReasonDefault},
+		{"prod", "new-ui", "", "false", ReasonDefault},
+		{"prod", "new-ui", "qa-1", "true", ReasonTarget},
+		// deny проверяется раньше allow и targets
+		{"prod", "new-ui", "blocked-1", "false", ReasonDeny},
+		{"prod", "new-ui", "blocked-2", "false", ReasonDeny},
+		// Окружения: dev меняет только default, staging - раскатку и deny
+		{"dev", "new-ui", "user-2", "true", ReasonDefault},
+		{"dev", "new-ui", "blocked-1", "true", ReasonDeny},
+		{"staging", "new-ui", "user-2", "true", ReasonRollout},
+		{"staging", "new-ui", "blocked-2", "true", ReasonTarget},
+		{"staging", "new-ui", "", "false", ReasonDefault},
+		// Мультивариантный флаг
+		{"prod", "theme", "designer-1", "neon", ReasonTarget},
+		{"prod", "theme", "", "classic", ReasonDefault},
+		{"prod", "off", "user-1", "false", ReasonDefault},
+		{"prod", "missing", "user-1", "", ReasonUnknown},
+	}
+
+	clients := make(map[string]*Client)
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%s/%s", tt.env, tt.key, tt.user), func(t *testing.T) {
+			c, ok := clients[tt.env]
+			if !ok {
+				c = newClient(t, testFlags, tt.env)
+				clients[tt.env] = c
+			}
+			ev := c.Evaluate(context.Background(), tt.key, tt.user)
+			if ev.Variant != tt.variant || ev.Reason != tt.reason {
+				t.Errorf("got %s (%s), want %s (%s)", ev.Variant, ev.Reason, tt.variant, tt.reason)
+			}
+		})
+	}
+}
+
+func TestBucketStable(t *testing.T) {
+	// Значения закреплены: смена хеша перетасует пользователей во всех раскатках
+	for user, want := range map[string]uint32{"user-1": 1474, "user-2": 3855, "user-3": 6236} {
+		if got := bucket("new-ui", user); got != want {
+			t.Errorf("bucket(new-ui, %s) = %d, want %d", user, got, want)
+		}
+	}
+
+	// Бакет зависит от флага: пользователи не попадают во все раскатки сразу
+	same := 0
+	for i := 0; i < 1000; i++ {
+		user := fmt.Sprintf("user-%d", i)
+		if bucket("a", user) == bucket("b", user) {
+			same++
+		}
+	}
+	if same > 10 {
+		t.Errorf("%d of 1000 users share buckets across flags", same)
+	}
+}
+
+func TestRolloutGrowsMonotonically(t *testing.T) {
+	flagsFor := func(pct int) *Client {
+		return newClient(t, fmt.Sprintf("flags:\n  f:\n    rollout: {\"true\": %d}\n", pct), "prod")
+	}
+	small, large := flagsFor(10), flagsFor(30)
+
+	enabled := 0
+	for i := 0; i < 10000; i++ {
+		user := fmt.Sprintf("user-%d", i)
+		inSmall := small.Bool(context.Background(), "f", user)
+		if inSmall {
+			enabled++
+		}
+		// Рост процента только добавляет пользователей
+		if inSmall && !large.Bool(context.Background(), "f", user) {
+			t.Fatalf("%s dropped out of rollout when it grew from 10%% to 30%%", user)
+		}
+		// Повторное вычисление дает тот же результат
+		if small.Bool(context.Background(), "f", user) != inSmall {
+			t.Fatalf("%s evaluation is not stable", user)
+		}
+	}
+	if enabled < 800 || enabled > 1200 {
+		t.Errorf("10%% rollout enabled %d of 10000 users", enabled)
+	}
+}
+
+func TestMultivariateDistribution(t *testing.T) {
+	c := newClient(t, testFlags, "prod")
+	counts := make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		counts[c.Variant(context.Background(), "theme", fmt.Sprintf("user-%d", i))]++
+	}
+	for variant, want := range map[string]int{"classic": 5000, "dark": 3000, "neon": 2000} {
+		if got := counts[variant]; got < want-300 || got > want+300 {
+			t.Errorf("%s: %d of 10000 users, want about %d", variant, got, want)
+		}
+	}
+}
+
+func TestMissingFile(t *testing.T) {
+	c, err := New(filepath.Join(t.TempDir(), "flags.yaml"), "dev")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if c.Bool(context.Background(), "new-ui", "user-1") {
+		t.Error("unknown flag must be false")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		flag    string
+		wantErr string
+	}{
+		{"unknown default", "default: maybe", `default: unknown variant "maybe"`},
+		{"rollout over 100", `rollout: {"true": 60, "false": 50}`, "percentages sum to 110.00"},
+		{"negative rollout", `rollout: {"true": -1}`, "negative percentage"},
+		{"allow on multivariate", "variants: [a, b]\n    allow: [u]", "allow is only supported for boolean flags"},
+		{"unknown target", "variants: [a, b]\n    targets: {c: [u]}", `targets: unknown variant "c"`},
+		{"duplicate variant", "variants: [a, a]", `duplicate variant "a"`},
+		{"environment override", "environments:\n      prod:\n        default: maybe", `environments.prod.default: unknown variant "maybe"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "flags.yaml")
+			if err := os.WriteFile(path, []byte("flags:\n  f:\n    "+tt.flag+"\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := New(path, "prod")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package flags
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Handler - admin-эндпоинт флагов:
+//
+//	GET /admin/flags                  - все флаги с правилами
+//	GET /admin/flags?key=K&user=U     - какой вариант получит пользователь и почему
+//
+// Вычисление через эндпоинт не пишется в метрики и спаны.
+func (c *Client) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if key := r.URL.Query().Get("key"); key != "" {
+			_ = json.NewEncoder(w).Encode(c.evaluate(key, r.URL.Query().Get("user")))
+			return
+		}
+
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"env":   c.env,
+			"file":  c.path,
+			"flags": c.flags,
+		})
+	})
+}
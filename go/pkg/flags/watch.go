@@ -0,0 +1,106 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce - редакторы и kubelet пишут файл в несколько событий
+const reloadDebounce = 200 * time.Millisecond
+
+// Watch перечитывает файл флагов при изменении, пока не отменен ctx.
+// Следит за директорией: так виден и появившийся позже файл, и замена симлинка ..data в k8s.
+// Невалидный файл отклоняется, продолжают действовать прежние флаги.
+func (c *Client) Watch(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stop != nil {
+		return errors.New("flags: Watch is already running")
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create flags watcher: %w", err)
+	}
+	dir := filepath.Dir(c.path)
+	if err := watcher.Add(dir); err != nil {
+		_ = watcher.Close()
+		return fmt.Errorf("failed to watch flags dir %s: %w", dir, err)
+	}
+
+	done := make(chan struct{})
+	c.stop = func() { close(done) }
+
+	go func() {
+		var timer *time.Timer
+		defer func() {
+			if timer != nil {
+				timer.Stop()
+			}
+			_ = watcher.Close()
+		}()
+
+		name := filepath.Base(c.path)
+		for {
+			select {
+			case <-ctx.Done():
+				c.mu.Lock()
+				c.stop = nil
+				c.mu.Unlock()
+				return
+			case <-done:
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				base := filepath.Base(event.Name)
+				if base != name && !strings.HasPrefix(base, "..") {
+					continue
+				}
+				if timer == nil {
+					timer = time.AfterFunc(reloadDebounce, c.reload)
+				} else {
+					timer.Reset(reloadDebounce)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				slog.Default().Warn("⚠️ Flags watcher error", "error", err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// reload перечитывает файл. Удаленный файл сбрасывает флаги в default.
+func (c *Client) reload() {
+	flags, err := readFile(c.path)
+	if err != nil {
+		slog.Default().Warn("⚠️ Flags change rejected, keeping previous flags", "path", c.path, "error", err)
+		return
+	}
+	if flags == nil {
+		flags = make(map[string]Flag)
+	}
+
+	c.mu.Lock()
+	changed := !reflect.DeepEqual(c.flags, flags)
+	c.flags = flags
+	c.mu.Unlock()
+
+	if changed {
+		slog.Default().Info("🔄 Feature flags reloaded", "path", c.path, "flags", len(flags))
+	}
+}
@@ -0,0 +1,41 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+)
+
+// Dial - проверка TCP-доступности: успех, если отвечает хотя бы один из адресов
+// (список брокеров Kafka, endpoint коллектора OTel).
+func Dial(addrs ...string) Check {
+	return func(ctx context.Context) error {
+		if len(addrs) == 0 {
+			return errors.New("no addresses configured")
+		}
+		var (
+			dialer net.Dialer
+			errs   []error
+		)
+		for _, addr := range addrs {
+			conn, err := dialer.DialContext(ctx, "tcp", addr)
+			if err == nil {
+				return conn.Close()
+			}
+			errs = append(errs, err)
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// File - проверка наличия файла или каталога (собранная статика, remoteEntry.js)
+func File(path string) Check {
+	return func(context.Context) error {
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("static asset unavailable: %w", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,87 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// grpcState - зарегистрированные health-серверы gRPC и их сервисы
+type grpcState struct {
+	mu      sync.Mutex
+	servers []*grpchealth.Server
+	names   [][]string
+}
+
+// RegisterGRPC регистрирует grpc.health.v1 на srv. Вызывается после регистрации сервисов: статус выставляется
+// для "" (весь сервер) и каждого сервиса из srv.GetServiceInfo и обновляется в Run.
+func (r *Registry) RegisterGRPC(srv *grpc.Server) {
+	hs := grpchealth.NewServer()
+	names := []string{""}
+	for name := range srv.GetServiceInfo() {
+		names = append(names, name)
+	}
+	healthpb.RegisterHealthServer(srv, hs)
+
+	r.mu.Lock()
+	if r.grpc == nil {
+		r.grpc = &grpcState{}
+	}
+	state := r.grpc
+	r.mu.Unlock()
+
+	state.mu.Lock()
+	state.servers = append(state.servers, hs)
+	state.names = append(state.names, names)
+	state.mu.Unlock()
+
+	r.setGRPCStatus(r.Ready(context.Background()).serving())
+}
+
+// Run периодически (раз в cacheTTL, но не чаще раза в секунду) пересчитывает готовность
+// и обновляет статусы grpc.health.v1: Watch у Envoy и клиентов получает изменения без опроса.
+func (r *Registry) Run(ctx context.Context) error {
+	interval := max(r.cacheTTL, time.Second)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			r.setGRPCStatus(r.Ready(ctx).serving())
+		}
+	}
+}
+
+// serving - готов ли сервис принимать трафик
+func (rep Report) serving() bool {
+	return rep.Status == StatusOK || rep.Status == StatusDegraded
+}
+
+func (r *Registry) setGRPCStatus(serving bool) {
+	r.mu.Lock()
+	state := r.grpc
+	r.mu.Unlock()
+	if state == nil {
+		return
+	}
+
+	status := healthpb.HealthCheckResponse_NOT_SERVING
+	if serving {
+		status = healthpb.HealthCheckResponse_SERVING
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	for i, hs := range state.servers {
+		for _, name := range state.names[i] {
+			hs.SetServingStatus(name, status)
+		}
+	}
+}
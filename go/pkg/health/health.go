@@ -0,0 +1,244 @@
+// Package health - реестр именованных проверок состояния сервиса (Kafka, коллектор OTel, статика, БД).
+// Проверки выполняются параллельно с таймаутом, результат кешируется на cacheTTL: частые пробы k8s и Envoy
+// не открывают соединение к брокеру на каждый запрос. Отдается как /livez и /readyz (HTTP) и grpc.health.v1.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Статусы проверки и сервиса
+const (
+	StatusOK       = "ok"
+	StatusFail     = "fail"
+	StatusDegraded = "degraded"      // Упала необязательная проверка (Optional), сервис готов
+	StatusStopping = "shutting_down" // Shutdown: трафик больше не принимается
+)
+
+// Check - проверка; ctx ограничен таймаутом проверки
+type Check func(ctx context.Context) error
+
+// Option настраивает проверку
+type Option func(*check)
+
+// Timeout переопределяет таймаут реестра для проверки
+func Timeout(d time.Duration) Option {
+	return func(c *check) { c.timeout = d }
+}
+
+// Optional - ошибка проверки не снимает готовность, сервис отчитывается как degraded
+// (коллектор телеметрии: без него сервис работает, теряются только трейсы).
+func Optional() Option {
+	return func(c *check) { c.optional = true }
+}
+
+// Liveness включает проверку в /livez. Падение liveness перезапускает под, поэтому зависимости
+// (Kafka, БД) сюда не добавляют - только состояние самого процесса.
+func Liveness() Option {
+	return func(c *check) { c.liveness = true }
+}
+
+type check struct {
+	name     string
+	fn       Check
+	timeout  time.Duration
+	optional bool
+	liveness bool
+}
+
+// Result - результат проверки
+type Result struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+	Optional   bool   `json:"optional,omitempty"`
+	CheckedAt  string `json:"checked_at"`
+
+	liveness bool
+}
+
+// Report - ответ /livez и /readyz
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+// Registry - набор проверок сервиса
+type Registry struct {
+	timeout  time.Duration
+	cacheTTL time.Duration
+	drain    time.Duration
+
+	mu        sync.Mutex
+	checks    []check
+	results   map[string]Result
+	checkedAt time.Time
+	stopping  bool
+
+	grpc *grpcState
+}
+
+// New создает реестр; timeout - таймаут проверки по умолчанию (<= 0 - 2 секунды),
+// cacheTTL - сколько переиспользовать результат (0 - проверять на каждый запрос),
+// drain - сколько Shutdown ждет после снятия готовности.
+func New(timeout, cacheTTL, drain time.Duration) *Registry {
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	return &Registry{timeout: timeout, cacheTTL: cacheTTL, drain: drain}
+}
+
+// Add регистрирует проверку name; по умолчанию она влияет только на готовность (/readyz)
+func (r *Registry) Add(name string, fn Check, opts ...Option) {
+	c := check{name: name, fn: fn, timeout: r.timeout}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, c)
+	r.checkedAt = time.Time{}
+}
+
+// Ready возвращает состояние готовности по всем проверкам
+func (r *Registry) Ready(ctx context.Context) Report {
+	return r.report(ctx, false)
+}
+
+// Live возвращает состояние по проверкам Liveness
+func (r *Registry) Live(ctx context.Context) Report {
+	return r.report(ctx, true)
+}
+
+func (r *Registry) report(ctx context.Context, liveOnly bool) Report {
+	results, stopping := r.run(ctx)
+
+	rep := Report{Status: StatusOK, Checks: make(map[string]Result, len(results))}
+	for name, res := range results {
+		if liveOnly && !res.liveness {
+			continue
+		}
+		rep.Checks[name] = res
+		switch {
+		case res.Status == StatusOK:
+		case res.Optional:
+			if rep.Status == StatusOK {
+				rep.Status = StatusDegraded
+			}
+		default:
+			rep.Status = StatusFail
+		}
+	}
+	if stopping && !liveOnly {
+		rep.Status = StatusStopping
+	}
+	return rep
+}
+
+// run выполняет проверки параллельно или возвращает кеш, если он моложе cacheTTL.
+// Проверки не прерываются отменой ctx запроса: результат попадает в кеш для следующих проб.
+func (r *Registry) run(ctx context.Context) (map[string]Result, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.results != nil && time.Since(r.checkedAt) < r.cacheTTL {
+		return r.results, r.stopping
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	results := make(map[string]Result, len(r.checks))
+	var (
+		wg  sync.WaitGroup
+		rmu sync.Mutex
+	)
+	for _, c := range r.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res := c.run(ctx)
+			rmu.Lock()
+			results[c.name] = res
+			rmu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	r.results = results
+	r.checkedAt = time.Now()
+	return results, r.stopping
+}
+
+func (c check) run(ctx context.Context) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.fn(ctx)
+	res := Result{
+		Status:     StatusOK,
+		DurationMS: time.Since(start).Milliseconds(),
+		Optional:   c.optional,
+		CheckedAt:  start.UTC().Format(time.RFC3339),
+		liveness:   c.liveness,
+	}
+	if err != nil {
+		res.Status = StatusFail
+		res.Error = err.Error()
+	}
+	return res
+}
+
+// Shutdown снимает готовность (/readyz отвечает 503, gRPC - NOT_SERVING) и ждет drain, пока пробы
+// и балансировщики не уберут под; серверы все это время обслуживают запросы. Регистрируется компонентом
+// app.Runner последним, чтобы остановиться первым. Ожидание прерывается дедлайном остановки.
+func (r *Registry) Shutdown(ctx context.Context) error {
+	r.mu.Lock()
+	r.stopping = true
+	r.mu.Unlock()
+
+	r.setGRPCStatus(false)
+	if r.drain <= 0 {
+		return nil
+	}
+
+	slog.Default().Info("⏳ Readiness withdrawn, draining", "drain", r.drain)
+	timer := time.NewTimer(r.drain)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// LiveHandler - /livez: 200, пока живы проверки Liveness (без них - всегда)
+func (r *Registry) LiveHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		writeReport(w, r.Live(req.Context()))
+	})
+}
+
+// ReadyHandler - /readyz: 503, если упала обязательная проверка или сервис останавливается
+func (r *Registry) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		writeReport(w, r.Ready(req.Context()))
+	})
+}
+
+func writeReport(w http.ResponseWriter, rep Report) {
+	status := http.StatusOK
+	if rep.Status == StatusFail || rep.Status == StatusStopping {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(rep)
+}
@@ -0,0 +1,218 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// counter - проверка, считающая вызовы; возвращает err
+func counter(n *atomic.Int32, err error) Check {
+	return func(context.Context) error {
+		n.Add(1)
+		return err
+	}
+}
+
+func get(t *testing.T, h http.Handler) (int, Report) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var rep Report
+	if err := json.Unmarshal(rec.Body.Bytes(), &rep); err != nil {
+		t.Fatalf("decode report: %v (%s)", err, rec.Body)
+	}
+	return rec.Code, rep
+}
+
+func TestCacheTTL(t *testing.T) {
+	var n atomic.Int32
+	r := New(time.Second, 50*time.Millisecond, 0)
+	r.Add("kafka", counter(&n, nil))
+
+	for range 3 {
+		r.Ready(context.Background())
+	}
+	if got := n.Load(); got != 1 {
+		t.Fatalf("check ran %d times within TTL, want 1", got)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	r.Ready(context.Background())
+	if got := n.Load(); got != 2 {
+		t.Errorf("check ran %d times after TTL, want 2", got)
+	}
+
+	// Новая проверка сбрасывает кеш
+	r.Add("static", counter(&n, nil))
+	r.Ready(context.Background())
+	if got := n.Load(); got != 4 {
+		t.Errorf("check ran %d times after Add, want 4", got)
+	}
+}
+
+func TestNoCache(t *testing.T) {
+	var n atomic.Int32
+	r := New(time.Second, 0, 0)
+	r.Add("kafka", counter(&n, nil))
+
+	for range 3 {
+		r.Ready(context.Background())
+	}
+	if got := n.Load(); got != 3 {
+		t.Errorf("check ran %d times with cacheTTL 0, want 3", got)
+	}
+}
+
+func TestOptionalAndRequired(t *testing.T) {
+	down := errors.New("connection refused")
+
+	tests := []struct {
+		name     string
+		optional error
+		required error
+		status   string
+		code     int
+	}{
+		{"all ok", nil, nil, StatusOK, http.StatusOK},
+		{"optional failed", down, nil, StatusDegraded, http.StatusOK},
+		{"required failed", nil, down, StatusFail, http.StatusServiceUnavailable},
+		{"both failed", down, down, StatusFail, http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var n atomic.Int32
+			r := New(time.Second, 0, 0)
+			r.Add("otel-collector", counter(&n, tt.optional), Optional())
+			r.Add("kafka", counter(&n, tt.required))
+
+			code, rep := get(t, r.ReadyHandler())
+			if code != tt.code || rep.Status != tt.status {
+				t.Errorf("/readyz = %d %s, want %d %s", code, rep.Status, tt.code, tt.status)
+			}
+			if c := rep.Checks["otel-collector"]; !c.Optional {
+				t.Errorf("otel-collector not marked optional: %+v", c)
+			}
+			if tt.required != nil && rep.Checks["kafka"].Error != down.Error() {
+				t.Errorf("kafka error = %q", rep.Checks["kafka"].Error)
+			}
+		})
+	}
+}
+
+func TestLivenessChecks(t *testing.T) {
+	var n atomic.Int32
+	r := New(time.Second, 0, 0)
+	r.Add("kafka", counter(&n, errors.New("down")))
+
+	// Зависимости не влияют на /livez
+	code, rep := get(t, r.LiveHandler())
+	if code != http.StatusOK || rep.Status != StatusOK || len(rep.Checks) != 0 {
+		t.Errorf("/livez = %d %+v, want 200 ok without checks", code, rep)
+	}
+
+	r.Add("deadlock", counter(&n, errors.New("stuck")), Liveness())
+	code, rep = get(t, r.LiveHandler())
+	if code != http.StatusServiceUnavailable || rep.Status != StatusFail {
+		t.Errorf("/livez = %d %s, want 503 fail", code, rep.Status)
+	}
+	if _, ok := rep.Checks["kafka"]; ok {
+		t.Error("/livez reports readiness-only check")
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	r := New(time.Second, 0, 0)
+	r.Add("slow", slow, Timeout(10*time.Millisecond))
+
+	start := time.Now()
+	rep := r.Ready(context.Background())
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Ready took %v, per-check timeout ignored", elapsed)
+	}
+	if res := rep.Checks["slow"]; res.Status != StatusFail || res.Error != context.DeadlineExceeded.Error() {
+		t.Errorf("slow = %+v, want deadline exceeded", res)
+	}
+}
+
+func TestShutdownDrain(t *testing.T) {
+	var n atomic.Int32
+	// Длинный TTL: готовность снимается и при закешированном результате
+	r := New(time.Second, time.Hour, 200*time.Millisecond)
+	r.Add("kafka", counter(&n, nil))
+
+	if code, _ := get(t, r.ReadyHandler()); code != http.StatusOK {
+		t.Fatalf("/readyz before shutdown = %d, want 200", code)
+	}
+
+	done := make(chan error, 1)
+	start := time.Now()
+	go func() { done <- r.Shutdown(context.Background()) }()
+
+	// Во время drain: /readyz сразу 503, /livez по-прежнему 200
+	deadline := time.Now().Add(100 * time.Millisecond)
+	for {
+		code, rep := get(t, r.ReadyHandler())
+		if code == http.StatusServiceUnavailable && rep.Status == StatusStopping {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("/readyz = %d %s during drain, want 503 %s", code, rep.Status, StatusStopping)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if code, rep := get(t, r.LiveHandler()); code != http.StatusOK || rep.Status != StatusOK {
+		t.Errorf("/livez = %d %s during drain, want 200 ok", code, rep.Status)
+	}
+	select {
+	case <-done:
+		t.Fatal("Shutdown returned before drain elapsed")
+	default:
+	}
+
+	if err := <-done; err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("Shutdown returned after %v, want >= drain", elapsed)
+	}
+}
+
+func TestShutdownContextCanceled(t *testing.T) {
+	r := New(time.Second, 0, time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := r.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown = %v, want deadline exceeded", err)
+	}
+	if rep := r.Ready(context.Background()); rep.Status != StatusStopping {
+		t.Errorf("status = %s, want %s", rep.Status, StatusStopping)
+	}
+}
+
+func TestShutdownWithoutDrain(t *testing.T) {
+	r := New(time.Second, 0, 0)
+
+	start := time.Now()
+	if err := r.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("Shutdown without drain took %v", elapsed)
+	}
+	if code, _ := get(t, r.ReadyHandler()); code != http.StatusServiceUnavailable {
+		t.Errorf("/readyz = %d, want 503", code)
+	}
+}
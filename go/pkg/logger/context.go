@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Ключи метаданных запроса: одинаковые во всех сервисах, чтобы искать по ним в Grafana
+const (
+	KeyRequestID   = "request_id"
+	KeyUserID      = "user_id"
+	KeyRoute       = "route"
+	KeyEventType   = "event_type"
+	KeyKafkaOffset = "kafka.offset"
+)
+
+type ctxAttrsKey struct{}
+
+// With возвращает контекст, записи с которым несут attrs (пары ключ-значение, как в slog).
+// Middleware и Kafka consumer вызывают его один раз, дальше любой logger.Info(ctx, ...) добавляет поля сам:
+//
+//	ctx = logger.With(ctx, logger.KeyEventType, eventName, logger.KeyKafkaOffset, m.Offset)
+//
+// Повторный ключ заменяет прежнее значение.
+func With(ctx context.Context, args ...any) context.Context {
+	added := slog.Group("", args...).Value.Group()
+	if len(added) == 0 {
+		return ctx
+	}
+
+	prev := attrsFromContext(ctx)
+	attrs := make([]slog.Attr, 0, len(prev)+len(added))
+	for _, a := range prev {
+		if !hasKey(added, a.Key) {
+			attrs = append(attrs, a)
+		}
+	}
+	attrs = append(attrs, added...)
+	return context.WithValue(ctx, ctxAttrsKey{}, attrs)
+}
+
+// FromContext возвращает логгер, записи которого несут поля из ctx (With, trace_id/span_id) -
+// для кода, который принимает *slog.Logger. Записи такого логгера всегда используют ctx,
+// контекст из InfoContext и т.п. игнорируется.
+func FromContext(ctx context.Context) *slog.Logger {
+	if len(attrsFromContext(ctx)) == 0 && !trace.SpanContextFromContext(ctx).IsValid() {
+		return Log
+	}
+	return slog.New(&boundHandler{next: Log.Handler(), ctx: ctx})
+}
+
+func attrsFromContext(ctx context.Context) []slog.Attr {
+	attrs, _ := ctx.Value(ctxAttrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+func hasKey(attrs []slog.Attr, key string) bool {
+	for _, a := range attrs {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// contextHandler добавляет в запись trace_id/span_id (связь логов с трейсами в Grafana, Trace to Logs)
+// и поля из logger.With. Читает их из контекста записи в Handle, а не создает логгер на каждый вызов.
+type contextHandler struct {
+	next slog.Handler
+}
+
+func (h *contextHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return h.next.Enabled(ctx, l)
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(
+			slog.String("trace_id", sc.TraceID().String()),
+			slog.String("span_id", sc.SpanID().String()),
+		)
+	}
+	if attrs := attrsFromContext(ctx); len(attrs) > 0 {
+		r.AddAttrs(attrs...)
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{next: h.next.WithGroup(name)}
+}
+
+// boundHandler подставляет сохраненный контекст в каждую запись (см. FromContext)
+type boundHandler struct {
+	next slog.Handler
+	ctx  context.Context
+}
+
+func (h *boundHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return h.next.Enabled(h.ctx, l)
+}
+
+func (h *boundHandler) Handle(_ context.Context, r slog.Record) error {
+	return h.next.Handle(h.ctx, r)
+}
+
+func (h *boundHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &boundHandler{next: h.next.WithAttrs(attrs), ctx: h.ctx}
+}
+
+func (h *boundHandler) WithGroup(name string) slog.Handler {
+	return &boundHandler{next: h.next.WithGroup(name), ctx: h.ctx}
+}
@@ -0,0 +1,169 @@
+package logger
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// level - текущий уровень логирования. LevelVar позволяет менять его на лету
+// (SetLevel из hot reload конфигурации, LevelHandler из admin-эндпоинта).
+var level = new(slog.LevelVar)
+
+// Состояние временного переопределения уровня через LevelHandler
+var (
+	levelMu    sync.Mutex
+	configured slog.Level  // уровень из конфигурации: к нему возвращается временное переопределение
+	revertAt   time.Time   // нулевой, если временного переопределения нет
+	revert     *time.Timer // таймер возврата к configured
+)
+
+// resetLevel выставляет уровень из конфигурации и отменяет переопределение (Init)
+func resetLevel(l slog.Level) {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	stopRevert()
+	configured = l
+	level.Set(l)
+}
+
+// SetLevel меняет уровень логирования без пересоздания логгера.
+// Используется подписчиком config.Loader.Watch при изменении log.level:
+// новое значение конфигурации отменяет переопределение через LevelHandler.
+func SetLevel(lvl string) {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	stopRevert()
+	configured = parseLevel(lvl)
+	applyLevel(configured, "config")
+}
+
+// applyLevel вызывается под levelMu
+func applyLevel(newLevel slog.Level, source string) {
+	oldLevel := level.Level()
+	if newLevel == oldLevel {
+		return
+	}
+
+	level.Set(newLevel)
+	if Log != nil {
+		Log.Warn("🔧 Log level changed", "old", levelName(oldLevel), "new", levelName(newLevel), "source", source)
+	}
+}
+
+// stopRevert вызывается под levelMu
+func stopRevert() {
+	if revert != nil {
+		revert.Stop()
+		revert = nil
+	}
+	revertAt = time.Time{}
+}
+
+// override выставляет уровень через admin-эндпоинт. d > 0 - на время, затем возврат к уровню конфигурации.
+func override(l slog.Level, d time.Duration) {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	stopRevert()
+	applyLevel(l, "admin")
+
+	if d <= 0 {
+		return
+	}
+	revertAt = time.Now().Add(d)
+	var t *time.Timer
+	t = time.AfterFunc(d, func() {
+		levelMu.Lock()
+		defer levelMu.Unlock()
+		if revert != t {
+			return // переопределение уже заменено или отменено
+		}
+		revert, revertAt = nil, time.Time{}
+		applyLevel(configured, "revert")
+	})
+	revert = t
+}
+
+// levelState - ответ LevelHandler
+type levelState struct {
+	Level      string     `json:"level"`
+	Configured string     `json:"configured"`
+	RevertAt   *time.Time `json:"revert_at,omitempty"`
+}
+
+func currentState() levelState {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	state := levelState{Level: levelName(level.Level()), Configured: levelName(configured)}
+	if !revertAt.IsZero() {
+		at := revertAt
+		state.RevertAt = &at
+	}
+	return state
+}
+
+// LevelHandler - admin-эндпоинт уровня логирования:
+//
+//	GET /admin/log/level                           - текущий уровень и уровень из конфигурации
+//	PUT /admin/log/level?level=debug               - до рестарта или изменения log.level в конфигурации
+//	PUT /admin/log/level?level=debug&duration=10m  - на 10 минут, затем возврат к уровню конфигурации
+//
+// Сам обработчик не проверяет доступ: монтируется только за telemetry.RequireToken (server.admin_token).
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			l, ok := lookupLevel(r.URL.Query().Get("level"))
+			if !ok {
+				http.Error(w, "level must be one of: debug, info, warn, error", http.StatusBadRequest)
+				return
+			}
+
+			var d time.Duration
+			if raw := r.URL.Query().Get("duration"); raw != "" {
+				var err error
+				if d, err = time.ParseDuration(raw); err != nil || d <= 0 {
+					http.Error(w, "duration must be a positive Go duration (30s, 10m)", http.StatusBadRequest)
+					return
+				}
+			}
+			override(l, d)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(currentState())
+	})
+}
+
+// lookupLevel разбирает уровень; в отличие от parseLevel неизвестное значение - ошибка
+func lookupLevel(lvl string) (slog.Level, bool) {
+	switch strings.ToLower(lvl) {
+	case "debug":
+		return slog.LevelDebug, true
+	case "info":
+		return slog.LevelInfo, true
+	case "warn":
+		return slog.LevelWarn, true
+	case "error":
+		return slog.LevelError, true
+	default:
+		return slog.LevelInfo, false
+	}
+}
+
+// parseLevel - уровень из конфигурации (уже провалидирован), неизвестное значение - info
+func parseLevel(lvl string) slog.Level {
+	l, _ := lookupLevel(lvl)
+	return l
+}
+
+func levelName(l slog.Level) string {
+	return strings.ToLower(l.String())
+}
@@ -2,71 +2,98 @@ package logger
 
 import (
 	"context"
+	"io"
 	"log/slog"
 	"os"
-	"strings"
-
-	"go.opentelemetry.io/otel/trace"
+	"time"
 )
 
 var Log *slog.Logger
 
+// Форматы вывода (log.format)
+const (
+	FormatJSON   = "json"   // Для сбора логов: OTel Collector, Fluent Bit
+	FormatText   = "text"   // Человекочитаемый, с цветами в терминале - для локальной разработки
+	FormatLogfmt = "logfmt" // key=value, удобно грепать
+)
+
+// Config - настройки логгера. Поля повторяют config.LogConfig (пакеты pkg не импортируют друг друга),
+// поэтому в main достаточно преобразования типов: logger.Config(cfg.Log).
+type Config struct {
+	Level      string
+	Format     string
+	RedactKeys []string
+
+	SampleInitial    int
+	SampleThereafter int
+	SampleSummary    time.Duration
+}
+
 // Init инициализирует логгер.
-// Приложение пишет ТОЛЬКО в stdout (JSON).
+// Приложение пишет в stdout, формат - cfg.Format (по умолчанию JSON).
 // Сбор логов делает OTel Collector (docker-compose) или Fluent Bit (k8s).
-func Init(serviceName string, level string) {
-	var logLevel slog.Level
-
-	switch strings.ToLower(level) {
-	case "debug":
-		logLevel = slog.LevelDebug
-	case "warn":
-		logLevel = slog.LevelWarn
-	case "error":
-		logLevel = slog.LevelError
-	default:
-		logLevel = slog.LevelInfo
-	}
-
-	opts := &slog.HandlerOptions{
-		Level: logLevel,
-	}
+// Если telemetry.Setup поднял логи (telemetry.logs), записи дополнительно уходят в OTLP (см. otel.go).
+func Init(serviceName string, cfg Config) {
+	resetLevel(parseLevel(cfg.Level))
 
-	jsonHandler := slog.NewJSONHandler(os.Stdout, opts)
+	var handler slog.Handler = teeHandler{newHandler(os.Stdout, cfg), newOTelHandler(serviceName, cfg)}
 
 	// КРИТИЧНО: используем "service.name" (стандарт OpenTelemetry)
-	Log = slog.New(jsonHandler).With(
+	handler = handler.WithAttrs([]slog.Attr{
 		slog.String("service.name", serviceName),
-	)
+	})
+
+	// Поля из контекста (trace_id, logger.With) - до tee: одинаковые в stdout и OTLP.
+	// Сэмплирование - снаружи: сводка пропущенных пишется напрямую в handler, с service.name
+	Log = slog.New(withSampling(&contextHandler{next: handler}, cfg))
 
 	slog.SetDefault(Log)
 }
 
-// withTrace добавляет TraceID и SpanID из контекста в поля лога
-// Это позволяет связывать логи с трейсами в Grafana (Trace to Logs)
-func withTrace(ctx context.Context) *slog.Logger {
-	spanContext := trace.SpanFromContext(ctx).SpanContext()
-	if spanContext.IsValid() {
-		return Log.With(
-			slog.String("trace_id", spanContext.TraceID().String()),
-			slog.String("span_id", spanContext.SpanID().String()),
-		)
+// newHandler создает обработчик формата cfg.Format. Уровень общий для всех - level (см. level.go),
+// маскирование секретов (см. redact.go) работает во всех форматах.
+func newHandler(w *os.File, cfg Config) slog.Handler {
+	opts := &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: newRedactor(cfg.RedactKeys),
+	}
+
+	switch cfg.Format {
+	case FormatText:
+		return newTextHandler(w, opts, useColor(w))
+	case FormatLogfmt:
+		return slog.NewTextHandler(w, opts)
+	default:
+		return slog.NewJSONHandler(w, opts)
+	}
+}
+
+// useColor - цвета только в терминале и без NO_COLOR (https://no-color.org)
+func useColor(w io.Writer) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
 	}
-	return Log
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
 }
 
+// Info, Error, Debug, Warn пишут запись с полями из ctx: trace_id/span_id и logger.With (см. context.go)
 func Info(ctx context.Context, msg string, args ...any) {
-	withTrace(ctx).InfoContext(ctx, msg, args...)
+	Log.InfoContext(ctx, msg, args...)
 }
 
 func Error(ctx context.Context, msg string, args ...any) {
-	withTrace(ctx).ErrorContext(ctx, msg, args...)
+	Log.ErrorContext(ctx, msg, args...)
 }
 
 func Debug(ctx context.Context, msg string, args ...any) {
-	withTrace(ctx).DebugContext(ctx, msg, args...)
+	Log.DebugContext(ctx, msg, args...)
 }
 
 func Warn(ctx context.Context, msg string, args ...any) {
-	withTrace(ctx).WarnContext(ctx, msg, args...)
+	Log.WarnContext(ctx, msg, args...)
 }
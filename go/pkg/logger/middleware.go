@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Заголовки с метаданными запроса. X-Request-Id генерирует Envoy (gateway), X-User-Id выставляет
+// gateway после аутентификации - сервисы ему доверяют и не проверяют сами.
+const (
+	HeaderRequestID = "X-Request-Id"
+	HeaderUserID    = "X-User-Id"
+)
+
+// HTTPMiddleware кладет в контекст запроса request_id, user_id и route (шаблон из mux: "/messages", "/admin/"),
+// поэтому любой logger.Info(r.Context(), ...) в обработчиках несет их сам. Запрос без X-Request-Id
+// (мимо gateway) получает сгенерированный; он же возвращается в заголовке ответа.
+func HTTPMiddleware(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(HeaderRequestID)
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+		w.Header().Set(HeaderRequestID, requestID)
+
+		_, route := mux.Handler(r)
+		args := []any{KeyRequestID, requestID, KeyRoute, route}
+		if userID := r.Header.Get(HeaderUserID); userID != "" {
+			args = append(args, KeyUserID, userID)
+		}
+
+		mux.ServeHTTP(w, r.WithContext(With(r.Context(), args...)))
+	})
+}
+
+// UnaryServerInterceptor - то же для gRPC: request_id и user_id из metadata (x-request-id, x-user-id),
+// route - полное имя метода (/helloworld.Greeter/SayHello)
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		requestID := first(md.Get(HeaderRequestID))
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+		args := []any{KeyRequestID, requestID, KeyRoute, info.FullMethod}
+		if userID := first(md.Get(HeaderUserID)); userID != "" {
+			args = append(args, KeyUserID, userID)
+		}
+
+		return handler(With(ctx, args...), req)
+	}
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// newRequestID - 16 случайных байт в hex, как у trace_id
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,199 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+)
+
+// otelHandler превращает записи slog в OTel log records и отдает их глобальному LoggerProvider
+// (telemetry.Setup с telemetry.logs). Пока провайдера нет, global.Logger выключен (Enabled = false)
+// и записи даже не преобразуются; после SetLoggerProvider он делегирует уже созданному логгеру,
+// поэтому порядок logger.Init и telemetry.Setup не важен.
+//
+// trace_id/span_id берутся SDK из контекста записи (Emit(ctx, ...)), поэтому одноименные поля
+// из contextHandler не дублируются в атрибутах; service.name - атрибут ресурса.
+type otelHandler struct {
+	logger otellog.Logger
+	redact func(groups []string, a slog.Attr) slog.Attr
+
+	attrs  []otellog.KeyValue // атрибуты из WithAttrs, уже преобразованные
+	groups []string           // группы из WithGroup: ключи пишутся как request.method
+}
+
+func newOTelHandler(serviceName string, cfg Config) *otelHandler {
+	return &otelHandler{
+		logger: global.Logger(serviceName),
+		redact: newRedactor(cfg.RedactKeys),
+	}
+}
+
+func (h *otelHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return l >= level.Level() && h.logger.Enabled(ctx, otellog.EnabledParameters{Severity: severity(l)})
+}
+
+func (h *otelHandler) Handle(ctx context.Context, r slog.Record) error {
+	var rec otellog.Record
+	rec.SetTimestamp(r.Time)
+	rec.SetObservedTimestamp(time.Now())
+	rec.SetSeverity(severity(r.Level))
+	rec.SetSeverityText(r.Level.String())
+	rec.SetBody(otellog.StringValue(h.redact(nil, slog.String(slog.MessageKey, r.Message)).Value.String()))
+
+	rec.AddAttributes(h.attrs...)
+	attrs := make([]otellog.KeyValue, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = h.appendAttr(attrs, h.groups, a)
+		return true
+	})
+	rec.AddAttributes(attrs...)
+
+	h.logger.Emit(ctx, rec)
+	return nil
+}
+
+func (h *otelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = h.attrs[:len(h.attrs):len(h.attrs)]
+	for _, a := range attrs {
+		h2.attrs = h.appendAttr(h2.attrs, h.groups, a)
+	}
+	return &h2
+}
+
+func (h *otelHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(h.groups[:len(h.groups):len(h.groups)], name)
+	return &h2
+}
+
+// appendAttr маскирует атрибут и добавляет его в kvs; группы разворачиваются в group.key
+func (h *otelHandler) appendAttr(kvs []otellog.KeyValue, groups []string, a slog.Attr) []otellog.KeyValue {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() != slog.KindGroup {
+		a = h.redact(groups, a)
+		a.Value = a.Value.Resolve()
+	}
+	if a.Equal(slog.Attr{}) {
+		return kvs
+	}
+
+	if len(groups) == 0 {
+		switch a.Key {
+		case "trace_id", "span_id", "service.name":
+			return kvs
+		}
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			groups = append(groups[:len(groups):len(groups)], a.Key)
+		}
+		for _, ga := range a.Value.Group() {
+			kvs = h.appendAttr(kvs, groups, ga)
+		}
+		return kvs
+	}
+
+	key := a.Key
+	if len(groups) > 0 {
+		key = strings.Join(groups, ".") + "." + key
+	}
+	return append(kvs, otellog.KeyValue{Key: key, Value: otelValue(a.Value)})
+}
+
+// otelValue преобразует значение slog в значение OTel.
+// Duration и Time пишутся строками ("250ms", RFC3339) - так же, как в stdout.
+func otelValue(v slog.Value) otellog.Value {
+	switch v.Kind() {
+	case slog.KindString:
+		return otellog.StringValue(v.String())
+	case slog.KindInt64:
+		return otellog.Int64Value(v.Int64())
+	case slog.KindUint64:
+		if u := v.Uint64(); u <= math.MaxInt64 {
+			return otellog.Int64Value(int64(u))
+		}
+		return otellog.StringValue(v.String())
+	case slog.KindFloat64:
+		return otellog.Float64Value(v.Float64())
+	case slog.KindBool:
+		return otellog.BoolValue(v.Bool())
+	case slog.KindDuration:
+		return otellog.StringValue(v.Duration().String())
+	case slog.KindTime:
+		return otellog.StringValue(v.Time().Format(time.RFC3339Nano))
+	}
+
+	switch x := v.Any().(type) {
+	case error:
+		return otellog.StringValue(x.Error())
+	case []byte:
+		return otellog.BytesValue(x)
+	case fmt.Stringer:
+		return otellog.StringValue(x.String())
+	default:
+		return otellog.StringValue(fmt.Sprint(x))
+	}
+}
+
+// severity сопоставляет уровни slog с OTel: Debug=-4 -> DEBUG(5), Info=0 -> INFO(9),
+// Warn=4 -> WARN(13), Error=8 -> ERROR(17); промежуточные уровни - DEBUG2, INFO3 и т.д.
+func severity(l slog.Level) otellog.Severity {
+	s := otellog.Severity(l + slog.Level(otellog.SeverityInfo))
+	switch {
+	case s < otellog.SeverityTrace1:
+		return otellog.SeverityTrace1
+	case s > otellog.SeverityFatal4:
+		return otellog.SeverityFatal4
+	}
+	return s
+}
+
+// teeHandler пишет каждую запись во все обработчики (stdout + OTLP)
+type teeHandler []slog.Handler
+
+func (t teeHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	for _, h := range t {
+		if h.Enabled(ctx, l) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t teeHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, h := range t {
+		if h.Enabled(ctx, r.Level) {
+			errs = append(errs, h.Handle(ctx, r))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (t teeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make(teeHandler, len(t))
+	for i, h := range t {
+		out[i] = h.WithAttrs(attrs)
+	}
+	return out
+}
+
+func (t teeHandler) WithGroup(name string) slog.Handler {
+	out := make(teeHandler, len(t))
+	for i, h := range t {
+		out[i] = h.WithGroup(name)
+	}
+	return out
+}
@@ -0,0 +1,151 @@
+package logger
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// redacted - чем заменяется маскируемое значение
+const redacted = "[REDACTED]"
+
+// DefaultRedactKeys - шаблоны ключей, если LogConfig.RedactKeys не задан (bootstrap-логгер до загрузки конфигурации)
+var DefaultRedactKeys = []string{"password", "passwd", "secret", "token", "authorization", "cookie", "api_key", "email"}
+
+// Детекторы значений: маскируются в любом строковом атрибуте, сообщении и тексте ошибки
+var (
+	// JWT: заголовок и payload - base64url от JSON, поэтому начинаются с eyJ ({")
+	jwtPattern = regexp.MustCompile(`eyJ[A-Za-z0-9_-]{5,}\.eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]*`)
+	// Номер карты: 13-19 цифр, допускаются пробелы и дефисы между группами; проверяется по Луну
+	cardPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+)
+
+// sensitive - обертка, которая в логах всегда выглядит как [REDACTED]
+type sensitive struct {
+	value any
+}
+
+// Sensitive оборачивает значение, которое нельзя писать в логи ни в каком формате и ни на каком уровне:
+//
+//	logger.Info(ctx, "📩 Message received", "text", logger.Sensitive(msg.Text))
+func Sensitive(v any) slog.LogValuer {
+	return sensitive{value: v}
+}
+
+func (sensitive) LogValue() slog.Value { return slog.StringValue(redacted) }
+
+// String и GoString - на случай fmt.Sprintf("%v", ...) мимо slog
+func (sensitive) String() string   { return redacted }
+func (sensitive) GoString() string { return redacted }
+
+// newRedactor возвращает ReplaceAttr, маскирующий:
+//   - значения ключей, содержащих один из шаблонов keys (без учета регистра): user_password, X-Auth-Token;
+//   - группы с таким ключом целиком (slog.Group("client_secret", ...), WithGroup("auth_token")): обработчики
+//     вызывают ReplaceAttr только для листовых атрибутов, поэтому маскируется каждый лист внутри группы;
+//   - JWT и номера карт внутри строк, ошибок и сообщения.
+func newRedactor(keys []string) func(groups []string, a slog.Attr) slog.Attr {
+	if len(keys) == 0 {
+		keys = DefaultRedactKeys
+	}
+	patterns := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" {
+			patterns = append(patterns, k)
+		}
+	}
+
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 {
+			switch a.Key {
+			case slog.TimeKey, slog.LevelKey, slog.SourceKey:
+				return a
+			case slog.MessageKey:
+				return slog.String(a.Key, scrub(a.Value.String()))
+			}
+		}
+
+		if sensitiveKey(patterns, a.Key) {
+			return slog.String(a.Key, redacted)
+		}
+		for _, g := range groups {
+			if sensitiveKey(patterns, g) {
+				return slog.String(a.Key, redacted)
+			}
+		}
+
+		switch a.Value.Kind() {
+		case slog.KindString:
+			if s := a.Value.String(); scrubbable(s) {
+				a.Value = slog.StringValue(scrub(s))
+			}
+		case slog.KindAny:
+			if err, ok := a.Value.Any().(error); ok {
+				if s := err.Error(); scrubbable(s) {
+					a.Value = slog.StringValue(scrub(s))
+				}
+			}
+		}
+		return a
+	}
+}
+
+// sensitiveKey - ключ содержит один из шаблонов (patterns в нижнем регистре)
+func sensitiveKey(patterns []string, key string) bool {
+	key = strings.ToLower(key)
+	for _, p := range patterns {
+		if strings.Contains(key, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// scrubbable - быстрая проверка перед регулярками: в строке есть eyJ или хотя бы 13 цифр
+func scrubbable(s string) bool {
+	if strings.Contains(s, "eyJ") {
+		return true
+	}
+	digits := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			if digits++; digits >= 13 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// scrub заменяет JWT и номера карт внутри строки
+func scrub(s string) string {
+	if !scrubbable(s) {
+		return s
+	}
+	s = jwtPattern.ReplaceAllString(s, redacted)
+	return cardPattern.ReplaceAllStringFunc(s, func(m string) string {
+		if luhn(m) {
+			return redacted
+		}
+		return m
+	})
+}
+
+// luhn проверяет контрольную сумму номера карты (пробелы и дефисы пропускаются)
+func luhn(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
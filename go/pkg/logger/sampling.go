@@ -0,0 +1,178 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// maxSampledKeys ограничивает число отслеживаемых сообщений: сообщения, собранные через fmt.Sprintf,
+// уникальны, и без лимита карта росла бы до следующей сводки. Сверх лимита записи не сэмплируются.
+const maxSampledKeys = 10000
+
+// sampler считает записи по ключу (уровень + сообщение) в окне в одну секунду:
+// первые initial проходят, дальше - каждая thereafter-я. Пропущенные суммируются
+// и раз в interval выводятся сводкой "🔇 Log messages suppressed by sampling" с уровнем пропущенных записей.
+type sampler struct {
+	initial    int
+	thereafter int
+
+	mu     sync.Mutex
+	counts map[string]*sampleCount
+}
+
+type sampleCount struct {
+	level      slog.Level
+	window     int64 // Unix-секунда текущего окна
+	n          int   // записей в окне
+	suppressed int64 // пропущено с последней сводки
+	seen       bool  // были записи с последней сводки
+}
+
+func newSampler(initial, thereafter int) *sampler {
+	return &sampler{
+		initial:    initial,
+		thereafter: thereafter,
+		counts:     make(map[string]*sampleCount),
+	}
+}
+
+// allow решает, выводить ли запись
+func (s *sampler) allow(l slog.Level, msg string, t time.Time) bool {
+	key := l.String() + " " + msg
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.counts[key]
+	if !ok {
+		if len(s.counts) >= maxSampledKeys {
+			return true
+		}
+		c = &sampleCount{level: l}
+		s.counts[key] = c
+	}
+	c.seen = true
+
+	if sec := t.Unix(); c.window != sec {
+		c.window, c.n = sec, 0
+	}
+	c.n++
+
+	if c.n <= s.initial {
+		return true
+	}
+	if s.thereafter > 0 && (c.n-s.initial)%s.thereafter == 0 {
+		return true
+	}
+	c.suppressed++
+	return false
+}
+
+// suppressed - пропущенные записи одного ключа за интервал
+type suppressed struct {
+	key   string
+	level slog.Level
+	n     int64
+}
+
+// flush возвращает число пропущенных записей по ключам и забывает ключи без записей за интервал
+func (s *sampler) flush() []suppressed {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []suppressed
+	for key, c := range s.counts {
+		if c.suppressed > 0 {
+			out = append(out, suppressed{key: key, level: c.level, n: c.suppressed})
+			c.suppressed = 0
+		}
+		if !c.seen {
+			delete(s.counts, key)
+			continue
+		}
+		c.seen = false
+	}
+	return out
+}
+
+// run раз в interval пишет сводку пропущенных записей в next (мимо сэмплирования)
+func (s *sampler) run(ctx context.Context, next slog.Handler, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.summarize(ctx, next, now, interval)
+		}
+	}
+}
+
+// summarize пишет сводку на момент now. Сводка пишется уровнем пропущенных записей:
+// при log.level=warn сводка по Warn не теряется.
+func (s *sampler) summarize(ctx context.Context, next slog.Handler, now time.Time, interval time.Duration) {
+	for _, sp := range s.flush() {
+		if !next.Enabled(ctx, sp.level) {
+			continue
+		}
+		r := slog.NewRecord(now, sp.level, "🔇 Log messages suppressed by sampling", 0)
+		r.AddAttrs(slog.String("sampled_msg", sp.key), slog.Int64("suppressed", sp.n), slog.Duration("interval", interval))
+		_ = next.Handle(ctx, r)
+	}
+}
+
+// samplingHandler пропускает записи через sampler. Ошибки (Error и выше) выводятся всегда.
+type samplingHandler struct {
+	next slog.Handler
+	s    *sampler
+}
+
+func (h *samplingHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return h.next.Enabled(ctx, l)
+}
+
+func (h *samplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < slog.LevelError && !h.s.allow(r.Level, r.Message, r.Time) {
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &samplingHandler{next: h.next.WithAttrs(attrs), s: h.s}
+}
+
+func (h *samplingHandler) WithGroup(name string) slog.Handler {
+	return &samplingHandler{next: h.next.WithGroup(name), s: h.s}
+}
+
+// Сводка текущего сэмплера; останавливается при повторном Init
+var (
+	samplingMu   sync.Mutex
+	stopSampling context.CancelFunc
+)
+
+// withSampling оборачивает next сэмплированием по cfg. SampleInitial <= 0 - без сэмплирования.
+func withSampling(next slog.Handler, cfg Config) slog.Handler {
+	samplingMu.Lock()
+	defer samplingMu.Unlock()
+
+	if stopSampling != nil {
+		stopSampling()
+		stopSampling = nil
+	}
+	if cfg.SampleInitial <= 0 {
+		return next
+	}
+
+	s := newSampler(cfg.SampleInitial, cfg.SampleThereafter)
+	if cfg.SampleSummary > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		stopSampling = cancel
+		go s.run(ctx, next, cfg.SampleSummary)
+	}
+	return &samplingHandler{next: next, s: s}
+}
@@ -0,0 +1,186 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordHandler сохраняет записи для проверок
+type recordHandler struct {
+	level slog.Level
+
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (h *recordHandler) Enabled(_ context.Context, l slog.Level) bool { return l >= h.level }
+
+func (h *recordHandler) Handle(_ context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, r)
+	return nil
+}
+
+func (h *recordHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h *recordHandler) WithGroup(string) slog.Handler      { return h }
+
+// attrs возвращает атрибуты записи по ключам
+func attrs(r slog.Record) map[string]slog.Value {
+	out := make(map[string]slog.Value)
+	r.Attrs(func(a slog.Attr) bool {
+		out[a.Key] = a.Value
+		return true
+	})
+	return out
+}
+
+func TestSamplerAllow(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name       string
+		initial    int
+		thereafter int
+		times      []time.Duration // моменты записей одного сообщения от start
+		want       int             // сколько записей прошло
+	}{
+		{"below initial", 3, 10, []time.Duration{0, 0, 0}, 3},
+		{"every thereafter", 2, 3, repeat(0, 11), 5}, // 1, 2, 5, 8, 11
+		{"thereafter zero drops rest", 2, 0, repeat(0, 10), 2},
+		{"new window resets", 2, 0, []time.Duration{0, 0, 0, time.Second, time.Second, time.Second}, 4},
+		{"same second", 1, 0, []time.Duration{0, 999 * time.Millisecond}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSampler(tt.initial, tt.thereafter)
+			passed := 0
+			for _, d := range tt.times {
+				if s.allow(slog.LevelInfo, "msg", start.Add(d)) {
+					passed++
+				}
+			}
+			if passed != tt.want {
+				t.Errorf("passed = %d, want %d", passed, tt.want)
+			}
+		})
+	}
+}
+
+func repeat(d time.Duration, n int) []time.Duration {
+	out := make([]time.Duration, n)
+	for i := range out {
+		out[i] = d
+	}
+	return out
+}
+
+func TestSamplerKeysByLevelAndMessage(t *testing.T) {
+	s := newSampler(1, 0)
+	now := time.Unix(1700000000, 0)
+
+	if !s.allow(slog.LevelInfo, "a", now) || !s.allow(slog.LevelWarn, "a", now) || !s.allow(slog.LevelInfo, "b", now) {
+		t.Fatal("first record of each level+message must pass")
+	}
+	if s.allow(slog.LevelInfo, "a", now) {
+		t.Error("second INFO a in the same second must be suppressed")
+	}
+}
+
+func TestSamplingHandlerPassThrough(t *testing.T) {
+	next := &recordHandler{level: slog.LevelDebug}
+	h := &samplingHandler{next: next, s: newSampler(2, 5)}
+	now := time.Unix(1700000000, 0)
+
+	for i := 0; i < 12; i++ {
+		_ = h.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "request", 0))
+		// Ошибки не сэмплируются
+		_ = h.Handle(context.Background(), slog.NewRecord(now, slog.LevelError, "failed", 0))
+	}
+
+	counts := make(map[string]int)
+	for _, r := range next.records {
+		counts[r.Message]++
+	}
+	// request: 1, 2, 7, 12
+	if counts["request"] != 4 {
+		t.Errorf("request passed %d times, want 4", counts["request"])
+	}
+	if counts["failed"] != 12 {
+		t.Errorf("failed passed %d times, want 12", counts["failed"])
+	}
+}
+
+func TestSamplerSummary(t *testing.T) {
+	s := newSampler(1, 0)
+	now := time.Unix(1700000000, 0)
+	for i := 0; i < 5; i++ {
+		s.allow(slog.LevelInfo, "request", now)
+		s.allow(slog.LevelWarn, "slow query", now)
+	}
+	s.allow(slog.LevelDebug, "tick", now)
+	s.allow(slog.LevelDebug, "tick", now)
+
+	next := &recordHandler{level: slog.LevelInfo}
+	summaryAt := now.Add(time.Minute)
+	s.summarize(context.Background(), next, summaryAt, time.Minute)
+
+	// DEBUG-сводка не пишется при log.level=info
+	if len(next.records) != 2 {
+		t.Fatalf("got %d summary records, want 2", len(next.records))
+	}
+	sort.Slice(next.records, func(i, j int) bool { return next.records[i].Level < next.records[j].Level })
+
+	want := []struct {
+		level slog.Level
+		key   string
+	}{
+		{slog.LevelInfo, "INFO request"},
+		{slog.LevelWarn, "WARN slow query"},
+	}
+	for i, w := range want {
+		r := next.records[i]
+		a := attrs(r)
+		if r.Level != w.level || r.Message != "🔇 Log messages suppressed by sampling" {
+			t.Errorf("record %d: %s %q", i, r.Level, r.Message)
+		}
+		if !r.Time.Equal(summaryAt) {
+			t.Errorf("record %d time = %v, want %v", i, r.Time, summaryAt)
+		}
+		if got := a["sampled_msg"].String(); got != w.key {
+			t.Errorf("record %d sampled_msg = %q, want %q", i, got, w.key)
+		}
+		if got := a["suppressed"].Int64(); got != 4 {
+			t.Errorf("record %d suppressed = %d, want 4", i, got)
+		}
+		if got := a["interval"].Duration(); got != time.Minute {
+			t.Errorf("record %d interval = %v, want 1m", i, got)
+		}
+	}
+
+	// Счетчики сброшены: следующая сводка пустая
+	next.records = nil
+	s.summarize(context.Background(), next, summaryAt.Add(time.Minute), time.Minute)
+	if len(next.records) != 0 {
+		t.Errorf("got %d records after reset, want 0", len(next.records))
+	}
+}
+
+func TestSamplerForgetsIdleKeys(t *testing.T) {
+	s := newSampler(1, 0)
+	s.allow(slog.LevelInfo, "once", time.Unix(1700000000, 0))
+
+	s.flush()
+	if len(s.counts) != 1 {
+		t.Fatalf("key dropped after the first interval, want kept")
+	}
+	s.flush()
+	if len(s.counts) != 0 {
+		t.Errorf("idle key kept after an interval without records")
+	}
+}
@@ -0,0 +1,172 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ANSI-цвета формата text
+const (
+	colorReset = "\x1b[0m"
+	colorDim   = "\x1b[2m"
+	colorRed   = "\x1b[31m"
+	colorGreen = "\x1b[32m"
+	colorYell  = "\x1b[33m"
+	colorCyan  = "\x1b[36m"
+	colorGray  = "\x1b[90m"
+)
+
+// textHandler - человекочитаемый формат для локальной разработки:
+//
+//	15:04:05.000 INF Starting service http_port=8081 service.name=landing-service
+type textHandler struct {
+	w     io.Writer
+	mu    *sync.Mutex // общий для копий из WithAttrs/WithGroup: строки не перемешиваются
+	opts  *slog.HandlerOptions
+	color bool
+
+	attrs  string   // уже отформатированные атрибуты из WithAttrs
+	groups []string // группы из WithGroup: ключи пишутся как request.method
+}
+
+func newTextHandler(w io.Writer, opts *slog.HandlerOptions, color bool) *textHandler {
+	return &textHandler{w: w, mu: &sync.Mutex{}, opts: opts, color: color}
+}
+
+func (h *textHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= h.opts.Level.Level()
+}
+
+func (h *textHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+
+	if !r.Time.IsZero() {
+		h.paint(&b, colorGray, r.Time.Format("15:04:05.000"))
+		b.WriteByte(' ')
+	}
+	h.paint(&b, levelColor(r.Level), levelAbbr(r.Level))
+	b.WriteByte(' ')
+	msg := r.Message
+	if h.opts.ReplaceAttr != nil {
+		// Как и встроенные обработчики, пропускаем сообщение через ReplaceAttr (маскирование)
+		msg = h.opts.ReplaceAttr(nil, slog.String(slog.MessageKey, msg)).Value.String()
+	}
+	b.WriteString(msg)
+
+	b.WriteString(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		h.appendAttr(&b, h.groups, a)
+		return true
+	})
+	b.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *textHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var b strings.Builder
+	for _, a := range attrs {
+		h.appendAttr(&b, h.groups, a)
+	}
+	h2 := *h
+	h2.attrs += b.String()
+	return &h2
+}
+
+func (h *textHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(h.groups[:len(h.groups):len(h.groups)], name)
+	return &h2
+}
+
+// appendAttr пишет " key=value"; группы разворачиваются в group.key=value
+func (h *textHandler) appendAttr(b *strings.Builder, groups []string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() != slog.KindGroup && h.opts.ReplaceAttr != nil {
+		a = h.opts.ReplaceAttr(groups, a)
+		a.Value = a.Value.Resolve()
+	}
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			groups = append(groups[:len(groups):len(groups)], a.Key)
+		}
+		for _, ga := range a.Value.Group() {
+			h.appendAttr(b, groups, ga)
+		}
+		return
+	}
+
+	prefix := ""
+	if len(groups) > 0 {
+		prefix = strings.Join(groups, ".") + "."
+	}
+	b.WriteByte(' ')
+	h.paint(b, colorDim, prefix+a.Key+"=")
+
+	var value string
+	if a.Value.Kind() == slog.KindTime {
+		value = a.Value.Time().Format(time.RFC3339)
+	} else {
+		value = a.Value.String()
+	}
+	if value == "" || strings.ContainsAny(value, " =\"\n\t") {
+		value = strconv.Quote(value)
+	}
+
+	if a.Key == "error" || a.Key == "err" {
+		h.paint(b, colorRed, value)
+		return
+	}
+	b.WriteString(value)
+}
+
+func (h *textHandler) paint(b *strings.Builder, color, s string) {
+	if !h.color {
+		b.WriteString(s)
+		return
+	}
+	b.WriteString(color)
+	b.WriteString(s)
+	b.WriteString(colorReset)
+}
+
+func levelAbbr(l slog.Level) string {
+	switch {
+	case l >= slog.LevelError:
+		return "ERR"
+	case l >= slog.LevelWarn:
+		return "WRN"
+	case l >= slog.LevelInfo:
+		return "INF"
+	default:
+		return "DBG"
+	}
+}
+
+func levelColor(l slog.Level) string {
+	switch {
+	case l >= slog.LevelError:
+		return colorRed
+	case l >= slog.LevelWarn:
+		return colorYell
+	case l >= slog.LevelInfo:
+		return colorGreen
+	default:
+		return colorCyan
+	}
+}
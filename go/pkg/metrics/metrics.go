@@ -0,0 +1,161 @@
+// Package metrics - бизнес-метрики сервисов чата: все имена, единицы и бакеты определены здесь,
+// сервисы только вызывают методы Chat и Notification. Инструменты создаются через otel.Meter,
+// поэтому NewChat/NewNotification вызываются после telemetry.Setup и попадают в /metrics.
+//
+// Имена в Prometheus (экспортер добавляет суффиксы единиц и _total):
+//
+//	chat_messages_posted_total{outcome}        - команды PostMessage по исходу (published, rejected, failed)
+//	chat_message_size_bytes                    - размер опубликованных сообщений
+//	chat_delivery_latency_seconds              - от создания события (domain.NewMessage) до записи в WebSocket
+//	notification_ws_connections                - открытые WebSocket-соединения
+//	notification_broadcast_duration_seconds    - рассылка одного сообщения всем клиентам
+//	notification_broadcast_failures_total      - клиенты, запись в которых не удалась
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Исходы команды PostMessage (атрибут outcome у chat.messages.posted)
+const (
+	OutcomePublished = "published" // Событие записано в Kafka
+	OutcomeRejected  = "rejected"  // Отклонено доменной валидацией
+	OutcomeFailed    = "failed"    // Ошибка сериализации или публикации
+)
+
+// Бакеты длительностей в секундах (как у http.server.request.duration)
+var durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.25, 0.5, 0.75, 1, 2.5, 5, 7.5, 10}
+
+// Бакеты размера сообщения в байтах
+var sizeBuckets = []float64{16, 64, 256, 1024, 4096, 16384, 65536}
+
+// Chat - метрики chat-сервиса. Методы безопасны для nil: сервис работает и без метрик.
+type Chat struct {
+	posted metric.Int64Counter
+	size   metric.Int64Histogram
+}
+
+// NewChat регистрирует метрики chat.*
+func NewChat() (*Chat, error) {
+	meter := otel.Meter("chat")
+
+	m := &Chat{}
+	var err error
+	if m.posted, err = meter.Int64Counter("chat.messages.posted",
+		metric.WithDescription("PostMessage commands by outcome (published, rejected, failed)"),
+		metric.WithUnit("{message}"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create chat metric: %w", err)
+	}
+	if m.size, err = meter.Int64Histogram("chat.message.size",
+		metric.WithDescription("Content size of published messages"),
+		metric.WithUnit("By"),
+		metric.WithExplicitBucketBoundaries(sizeBuckets...),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create chat metric: %w", err)
+	}
+	return m, nil
+}
+
+// MessagePosted учитывает команду PostMessage; размер пишется только для опубликованных сообщений
+func (m *Chat) MessagePosted(ctx context.Context, outcome string, size int) {
+	if m == nil {
+		return
+	}
+	m.posted.Add(ctx, 1, metric.WithAttributes(attribute.String("outcome", outcome)))
+	if outcome == OutcomePublished {
+		m.size.Record(ctx, int64(size))
+	}
+}
+
+// Notification - метрики notification-сервиса. Методы безопасны для nil.
+type Notification struct {
+	duration metric.Float64Histogram
+	failures metric.Int64Counter
+	latency  metric.Float64Histogram
+
+	registration metric.Registration
+}
+
+// NewNotification регистрирует метрики notification.* и chat.delivery.latency;
+// connections возвращает число открытых WebSocket-соединений и вызывается при каждом сборе (scrape /metrics).
+func NewNotification(connections func() int) (*Notification, error) {
+	meter := otel.Meter("notification")
+
+	m := &Notification{}
+	var err error
+	if m.duration, err = meter.Float64Histogram("notification.broadcast.duration",
+		metric.WithDescription("Time to write one message to all WebSocket clients"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(durationBuckets...),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create notification metric: %w", err)
+	}
+	if m.failures, err = meter.Int64Counter("notification.broadcast.failures",
+		metric.WithDescription("WebSocket writes that failed during broadcast (client is disconnected)"),
+		metric.WithUnit("{failure}"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create notification metric: %w", err)
+	}
+	if m.latency, err = meter.Float64Histogram("chat.delivery.latency",
+		metric.WithDescription("Time from message creation in chat to its delivery to WebSocket clients"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(durationBuckets...),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create notification metric: %w", err)
+	}
+
+	conns, err := meter.Int64ObservableGauge("notification.ws.connections",
+		metric.WithDescription("Open WebSocket connections"),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create notification metric: %w", err)
+	}
+	m.registration, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		o.ObserveInt64(conns, int64(connections()))
+		return nil
+	}, conns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register notification metrics: %w", err)
+	}
+	return m, nil
+}
+
+// Broadcast учитывает рассылку: длительность записи всем клиентам и число неудачных записей
+func (m *Notification) Broadcast(ctx context.Context, elapsed time.Duration, failures int) {
+	if m == nil {
+		return
+	}
+	m.duration.Record(ctx, elapsed.Seconds())
+	if failures > 0 {
+		m.failures.Add(ctx, int64(failures))
+	}
+}
+
+// Delivered учитывает сквозную задержку доставки сообщения, созданного в createdAt.
+// Часы chat и notification не синхронизированы точнее NTP: отрицательные значения (расхождение часов) отбрасываются.
+func (m *Notification) Delivered(ctx context.Context, createdAt time.Time) {
+	if m == nil || createdAt.IsZero() {
+		return
+	}
+	latency := time.Since(createdAt)
+	if latency < 0 {
+		return
+	}
+	m.latency.Record(ctx, latency.Seconds())
+}
+
+// Close снимает callback notification.ws.connections
+func (m *Notification) Close() error {
+	if m == nil {
+		return nil
+	}
+	return m.registration.Unregister()
+}
@@ -0,0 +1,169 @@
+package telemetry
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/credentials"
+)
+
+// Экспортеры трейсов и логов (telemetry.exporter)
+const (
+	ExporterOTLPGRPC = "otlp-grpc" // OTel Collector, порт 4317
+	ExporterOTLPHTTP = "otlp-http" // OTel Collector или облако за HTTP-прокси, порт 4318
+	ExporterStdout   = "stdout"    // Дерево спанов в консоль - отладка без стека observability
+	ExporterFile     = "file"      // JSONL с ротацией (export_file)
+)
+
+// CollectorEndpoint - адрес OTLP-коллектора, если трейсы или логи отправляются в него; иначе пусто.
+// Используется проверкой готовности (pkg/health).
+func (cfg Config) CollectorEndpoint() string {
+	if !cfg.Traces && !cfg.Logs {
+		return ""
+	}
+	switch cfg.Exporter {
+	case ExporterOTLPGRPC, ExporterOTLPHTTP, "":
+		return cfg.OtelEndpoint
+	default:
+		return ""
+	}
+}
+
+// newTraceExporter создает экспортер трейсов по cfg.Exporter.
+// OTLP-клиенты подключаются лениво: коллектор может подняться позже сервиса,
+// ошибки отправки пишутся в лог (см. Setup).
+func newTraceExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case ExporterOTLPGRPC, "":
+		opts := []otlptracegrpc.Option{
+			otlptracegrpc.WithEndpoint(cfg.OtelEndpoint),
+			otlptracegrpc.WithHeaders(parseHeaders(cfg.OtelHeaders)),
+		}
+		if cfg.OtelInsecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		} else {
+			tlsCfg, err := newTLSConfig(cfg)
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsCfg)))
+		}
+		return otlptracegrpc.New(ctx, opts...)
+
+	case ExporterOTLPHTTP:
+		opts := []otlptracehttp.Option{
+			otlptracehttp.WithEndpoint(cfg.OtelEndpoint),
+			otlptracehttp.WithHeaders(parseHeaders(cfg.OtelHeaders)),
+		}
+		if cfg.OtelInsecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		} else {
+			tlsCfg, err := newTLSConfig(cfg)
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, otlptracehttp.WithTLSClientConfig(tlsCfg))
+		}
+		return otlptracehttp.New(ctx, opts...)
+
+	case ExporterStdout:
+		return newStdoutExporter(os.Stdout), nil
+
+	case ExporterFile:
+		return newFileExporter(cfg.ExportFile, cfg.ExportFileMaxSize, cfg.ExportFileBackups)
+
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", cfg.Exporter)
+	}
+}
+
+// newLogExporter создает OTLP-экспортер логов. Для stdout и file логов нет: они и так в stdout.
+func newLogExporter(ctx context.Context, cfg Config) (log.Exporter, error) {
+	switch cfg.Exporter {
+	case ExporterOTLPGRPC, "":
+		opts := []otlploggrpc.Option{
+			otlploggrpc.WithEndpoint(cfg.OtelEndpoint),
+			otlploggrpc.WithHeaders(parseHeaders(cfg.OtelHeaders)),
+		}
+		if cfg.OtelInsecure {
+			opts = append(opts, otlploggrpc.WithInsecure())
+		} else {
+			tlsCfg, err := newTLSConfig(cfg)
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, otlploggrpc.WithTLSCredentials(credentials.NewTLS(tlsCfg)))
+		}
+		return otlploggrpc.New(ctx, opts...)
+
+	case ExporterOTLPHTTP:
+		opts := []otlploghttp.Option{
+			otlploghttp.WithEndpoint(cfg.OtelEndpoint),
+			otlploghttp.WithHeaders(parseHeaders(cfg.OtelHeaders)),
+		}
+		if cfg.OtelInsecure {
+			opts = append(opts, otlploghttp.WithInsecure())
+		} else {
+			tlsCfg, err := newTLSConfig(cfg)
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, otlploghttp.WithTLSClientConfig(tlsCfg))
+		}
+		return otlploghttp.New(ctx, opts...)
+
+	default:
+		return nil, fmt.Errorf("log export requires an OTLP exporter, got %q", cfg.Exporter)
+	}
+}
+
+// newTLSConfig - TLS до коллектора: системные корневые сертификаты или otel_ca_file,
+// клиентский сертификат для mTLS - otel_cert_file + otel_key_file
+func newTLSConfig(cfg Config) (*tls.Config, error) {
+	tlsCfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if cfg.OtelCAFile != "" {
+		pem, err := os.ReadFile(cfg.OtelCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read collector CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.OtelCAFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	if cfg.OtelCertFile != "" || cfg.OtelKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.OtelCertFile, cfg.OtelKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsCfg, nil
+}
+
+// parseHeaders разбирает otel_headers в формате OTEL_EXPORTER_OTLP_HEADERS:
+// "x-api-key=secret,x-scope-orgid=tenant-1"
+func parseHeaders(s string) map[string]string {
+	headers := make(map[string]string)
+	for _, p := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(p, "=")
+		if k = strings.TrimSpace(k); ok && k != "" {
+			headers[k] = strings.TrimSpace(v)
+		}
+	}
+	return headers
+}
@@ -0,0 +1,186 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// fileExporter пишет спаны в JSONL: одна строка - один спан. Когда файл превышает maxSize мегабайт,
+// он переименовывается в <file>.1 (старые сдвигаются до <file>.<backups>), запись продолжается в новый.
+//
+//	jq 'select(.trace_id == "4bf92f...")' traces.jsonl
+type fileExporter struct {
+	path    string
+	maxSize int64
+	backups int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// fileSpan - строка JSONL
+type fileSpan struct {
+	TraceID       string            `json:"trace_id"`
+	SpanID        string            `json:"span_id"`
+	ParentSpanID  string            `json:"parent_span_id,omitempty"`
+	Name          string            `json:"name"`
+	Kind          string            `json:"kind"`
+	Service       string            `json:"service"`
+	Start         time.Time         `json:"start"`
+	DurationMs    float64           `json:"duration_ms"`
+	Status        string            `json:"status"`
+	StatusMessage string            `json:"status_message,omitempty"`
+	Attributes    map[string]any    `json:"attributes,omitempty"`
+	Events        []fileSpanEvent   `json:"events,omitempty"`
+	Resource      map[string]string `json:"resource,omitempty"`
+}
+
+type fileSpanEvent struct {
+	Name       string         `json:"name"`
+	Time       time.Time      `json:"time"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+func newFileExporter(path string, maxSizeMB, backups int) (*fileExporter, error) {
+	e := &fileExporter{
+		path:    path,
+		maxSize: int64(maxSizeMB) << 20,
+		backups: backups,
+	}
+	if err := e.open(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *fileExporter) ExportSpans(_ context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.f == nil {
+		return fmt.Errorf("trace file %s is closed", e.path)
+	}
+
+	for _, s := range spans {
+		line, err := json.Marshal(toFileSpan(s))
+		if err != nil {
+			return err
+		}
+		line = append(line, '\n')
+
+		if e.maxSize > 0 && e.size > 0 && e.size+int64(len(line)) > e.maxSize {
+			if err := e.rotate(); err != nil {
+				return err
+			}
+		}
+		n, err := e.f.Write(line)
+		e.size += int64(n)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *fileExporter) Shutdown(context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.f == nil {
+		return nil
+	}
+	err := e.f.Close()
+	e.f = nil
+	return err
+}
+
+// open открывает файл на дозапись; вызывается под mu (или до начала экспорта)
+func (e *fileExporter) open() error {
+	f, err := os.OpenFile(e.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open trace file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to stat trace file: %w", err)
+	}
+	e.f, e.size = f, info.Size()
+	return nil
+}
+
+// rotate: file.2 -> file.3, file.1 -> file.2, file -> file.1; самый старый удаляется. Вызывается под mu.
+func (e *fileExporter) rotate() error {
+	if err := e.f.Close(); err != nil {
+		return err
+	}
+	e.f = nil
+
+	if e.backups <= 0 {
+		if err := os.Remove(e.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return e.open()
+	}
+
+	_ = os.Remove(fmt.Sprintf("%s.%d", e.path, e.backups))
+	for i := e.backups - 1; i >= 1; i-- {
+		_ = os.Rename(fmt.Sprintf("%s.%d", e.path, i), fmt.Sprintf("%s.%d", e.path, i+1))
+	}
+	if err := os.Rename(e.path, e.path+".1"); err != nil {
+		return fmt.Errorf("failed to rotate trace file: %w", err)
+	}
+	return e.open()
+}
+
+func toFileSpan(s sdktrace.ReadOnlySpan) fileSpan {
+	fs := fileSpan{
+		TraceID:       s.SpanContext().TraceID().String(),
+		SpanID:        s.SpanContext().SpanID().String(),
+		Name:          s.Name(),
+		Kind:          s.SpanKind().String(),
+		Start:         s.StartTime(),
+		DurationMs:    float64(s.EndTime().Sub(s.StartTime()).Microseconds()) / 1000,
+		Status:        s.Status().Code.String(),
+		StatusMessage: s.Status().Description,
+	}
+	if s.Parent().IsValid() {
+		fs.ParentSpanID = s.Parent().SpanID().String()
+	}
+
+	if attrs := s.Attributes(); len(attrs) > 0 {
+		fs.Attributes = make(map[string]any, len(attrs))
+		for _, kv := range attrs {
+			fs.Attributes[string(kv.Key)] = kv.Value.AsInterface()
+		}
+	}
+	for _, ev := range s.Events() {
+		fe := fileSpanEvent{Name: ev.Name, Time: ev.Time}
+		if len(ev.Attributes) > 0 {
+			fe.Attributes = make(map[string]any, len(ev.Attributes))
+			for _, kv := range ev.Attributes {
+				fe.Attributes[string(kv.Key)] = kv.Value.AsInterface()
+			}
+		}
+		fs.Events = append(fs.Events, fe)
+	}
+
+	if res := s.Resource(); res != nil {
+		fs.Resource = make(map[string]string, res.Len())
+		for _, kv := range res.Attributes() {
+			if kv.Key == "service.name" {
+				fs.Service = kv.Value.AsString()
+				continue
+			}
+			fs.Resource[string(kv.Key)] = kv.Value.Emit()
+		}
+	}
+	return fs
+}
@@ -0,0 +1,20 @@
+package telemetry
+
+import (
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+)
+
+// GRPCServerOption подключает otelgrpc к серверу: спан на каждый вызов и RED-метрики
+// rpc.server.duration, rpc.server.request.size, rpc.server.response.size с атрибутами
+// rpc.service, rpc.method, rpc.grpc.status_code.
+func GRPCServerOption() grpc.ServerOption {
+	return grpc.StatsHandler(otelgrpc.NewServerHandler())
+}
+
+// GRPCDialOption - то же для клиента (rpc.client.*) и traceparent в исходящих вызовах:
+//
+//	conn, err := grpc.NewClient(addr, telemetry.GRPCDialOption(), ...)
+func GRPCDialOption() grpc.DialOption {
+	return grpc.WithStatsHandler(otelgrpc.NewClientHandler())
+}
@@ -0,0 +1,50 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"time"
+)
+
+// startPprof поднимает net/http/pprof на отдельном адресе cfg.PprofAddr - для окружений без Pyroscope:
+//
+//	go tool pprof -http=: -H 'Authorization: Bearer <pprof_token>' http://localhost:6060/debug/pprof/heap
+//
+// Адрес не должен совпадать с портом сервиса: наружу через gateway он не публикуется.
+// Без pprof_token listener не поднимается - профили раскрывают код и данные в памяти.
+func startPprof(cfg Config) (func(context.Context) error, error) {
+	if cfg.PprofToken == "" {
+		return nil, errors.New("pprof_addr is set but pprof_token is empty")
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	lis, err := net.Listen("tcp", cfg.PprofAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen pprof on %s: %w", cfg.PprofAddr, err)
+	}
+
+	srv := &http.Server{
+		Handler:           RequireToken(cfg.PprofToken, "pprof", mux),
+		ReadHeaderTimeout: 5 * time.Second,
+		// WriteTimeout не задаем: /debug/pprof/profile?seconds=30 пишет ответ через 30 секунд
+	}
+	go func() {
+		if err := srv.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Default().Error("❌ pprof server failed", "error", err)
+		}
+	}()
+	slog.Default().Info("🩺 pprof listening", "addr", lis.Addr().String())
+
+	return srv.Shutdown, nil
+}
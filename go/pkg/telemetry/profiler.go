@@ -0,0 +1,177 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"slices"
+	"sync"
+
+	"github.com/grafana/pyroscope-go"
+	"google.golang.org/grpc"
+)
+
+// Типы профилей (telemetry.profile_types), совпадают с pyroscope.ProfileType
+const (
+	ProfileCPU           = "cpu"
+	ProfileAllocObjects  = "alloc_objects"
+	ProfileAllocSpace    = "alloc_space"
+	ProfileInuseObjects  = "inuse_objects"
+	ProfileInuseSpace    = "inuse_space"
+	ProfileGoroutines    = "goroutines"
+	ProfileMutexCount    = "mutex_count"    // Требует runtime.SetMutexProfileFraction - заметная нагрузка
+	ProfileMutexDuration = "mutex_duration" // -//-
+	ProfileBlockCount    = "block_count"    // Требует runtime.SetBlockProfileRate - заметная нагрузка
+	ProfileBlockDuration = "block_duration" // -//-
+)
+
+// Частота выборки mutex/block, когда эти профили включены (значения из документации Pyroscope)
+const (
+	mutexProfileFraction = 5
+	blockProfileRate     = 5
+)
+
+// profilerState - запущенный профайлер и настройки, с которыми он запущен
+type profilerState struct {
+	serviceName string
+	endpoint    string
+	types       []string
+}
+
+var (
+	profilerMu sync.Mutex
+	profiler   *pyroscope.Profiler
+	profilerOn profilerState
+)
+
+// StartProfiler запускает отправку профилей в Pyroscope (cfg.PyroscopeEndpoint) или перезапускает ее,
+// если изменились адрес или cfg.ProfileTypes. Пустой адрес останавливает профилирование.
+// Вызывается из Setup и подписчиком config.Loader.Watch.
+//
+// Выборка mutex/block в рантайме включается, только если эти типы есть в cfg.ProfileTypes, -
+// и тогда же их отдает /debug/pprof (см. pprof.go).
+func StartProfiler(cfg Config) error {
+	profilerMu.Lock()
+	defer profilerMu.Unlock()
+
+	setProfileRates(cfg.ProfileTypes)
+
+	want := profilerState{serviceName: cfg.ServiceName, endpoint: cfg.PyroscopeEndpoint, types: cfg.ProfileTypes}
+	if profiler != nil && want.serviceName == profilerOn.serviceName && want.endpoint == profilerOn.endpoint &&
+		slices.Equal(want.types, profilerOn.types) {
+		return nil
+	}
+
+	if err := stopProfiler(); err != nil {
+		return err
+	}
+	if want.endpoint == "" {
+		return nil
+	}
+
+	types := make([]pyroscope.ProfileType, 0, len(want.types))
+	for _, t := range want.types {
+		types = append(types, pyroscope.ProfileType(t))
+	}
+
+	hostname, _ := os.Hostname()
+
+	p, err := pyroscope.Start(pyroscope.Config{
+		ApplicationName: want.serviceName,
+		ServerAddress:   want.endpoint,
+		Logger:          nil,
+		Tags: map[string]string{
+			"hostname": hostname,
+			"go_os":    runtime.GOOS,
+			"go_arch":  runtime.GOARCH,
+		},
+		ProfileTypes: types,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start pyroscope profiler at %s: %w", want.endpoint, err)
+	}
+
+	profiler = p
+	profilerOn = want
+	return nil
+}
+
+// StopProfiler останавливает профилирование, отправив накопленные профили.
+// ctx ограничивает ожидание отправки: недоступный Pyroscope не задерживает остановку сервиса.
+func StopProfiler(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		profilerMu.Lock()
+		defer profilerMu.Unlock()
+		done <- stopProfiler()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("failed to flush pyroscope profiles: %w", ctx.Err())
+	}
+}
+
+// stopProfiler вызывается под profilerMu
+func stopProfiler() error {
+	if profiler == nil {
+		return nil
+	}
+	err := profiler.Stop()
+	profiler = nil
+	profilerOn = profilerState{}
+	if err != nil {
+		return fmt.Errorf("failed to stop pyroscope profiler: %w", err)
+	}
+	return nil
+}
+
+// setProfileRates включает выборку mutex/block только для выбранных профилей
+func setProfileRates(types []string) {
+	mutex, block := 0, 0
+	if slices.Contains(types, ProfileMutexCount) || slices.Contains(types, ProfileMutexDuration) {
+		mutex = mutexProfileFraction
+	}
+	if slices.Contains(types, ProfileBlockCount) || slices.Contains(types, ProfileBlockDuration) {
+		block = blockProfileRate
+	}
+	runtime.SetMutexProfileFraction(mutex)
+	runtime.SetBlockProfileRate(block)
+}
+
+// TagProfile выполняет fn с метками профиля: CPU-время внутри fn (и запущенных из нее горутин)
+// в Pyroscope и /debug/pprof можно отфильтровать по ним. Имена меток - без точек:
+//
+//	telemetry.TagProfile(ctx, func(ctx context.Context) { ... }, "tenant", tenantID)
+func TagProfile(ctx context.Context, fn func(context.Context), labels ...string) {
+	pyroscope.TagWrapper(ctx, pyroscope.Labels(labels...), fn)
+}
+
+// ProfileHTTPMiddleware помечает профиль запроса шаблоном маршрута mux (http_route), next обычно -
+// logger.HTTPMiddleware(mux). Путь запроса в метку не попадает: у /users/42 и /users/43 один шаблон.
+func ProfileHTTPMiddleware(mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, route := mux.Handler(r)
+		if route == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		TagProfile(r.Context(), func(ctx context.Context) {
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}, "http_route", route)
+	})
+}
+
+// ProfileUnaryInterceptor помечает профиль вызова gRPC-методом (rpc_method)
+func ProfileUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		TagProfile(ctx, func(ctx context.Context) {
+			resp, err = handler(ctx, req)
+		}, "rpc_method", info.FullMethod)
+		return resp, err
+	}
+}
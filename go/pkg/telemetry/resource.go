@@ -0,0 +1,102 @@
+package telemetry
+
+import (
+	"context"
+	"os"
+	"runtime/debug"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+// Переменные окружения пода, которые k8s-манифесты заполняют через Downward API (fieldRef)
+const (
+	envPodName   = "K8S_POD_NAME"
+	envNamespace = "K8S_NAMESPACE_NAME"
+	envNodeName  = "K8S_NODE_NAME"
+)
+
+// newResource описывает процесс для всех сигналов:
+//   - service.name, service.version и vcs.revision - из build info (см. buildVersion);
+//   - service.instance.id и k8s.pod.name, k8s.namespace.name, k8s.node.name - из окружения пода;
+//   - host.name, os.type, process.runtime.*, telemetry.sdk.*.
+//
+// OTEL_RESOURCE_ATTRIBUTES применяется последним и перекрывает все остальное
+// (например, service.version=1.4.2 из CI).
+func newResource(ctx context.Context, serviceName string) (*resource.Resource, error) {
+	version, revision, modified := buildVersion()
+
+	attrs := []attribute.KeyValue{
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(version),
+	}
+	if revision != "" {
+		attrs = append(attrs,
+			attribute.String("vcs.revision", revision),
+			attribute.Bool("vcs.modified", modified),
+		)
+	}
+
+	if pod := os.Getenv(envPodName); pod != "" {
+		attrs = append(attrs, semconv.K8SPodName(pod), semconv.ServiceInstanceID(pod))
+	} else if hostname, err := os.Hostname(); err == nil {
+		attrs = append(attrs, semconv.ServiceInstanceID(hostname))
+	}
+	if ns := os.Getenv(envNamespace); ns != "" {
+		attrs = append(attrs, semconv.K8SNamespaceName(ns))
+	}
+	if node := os.Getenv(envNodeName); node != "" {
+		attrs = append(attrs, semconv.K8SNodeName(node))
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithHost(),
+		resource.WithOSType(),
+		resource.WithProcessRuntimeName(),
+		resource.WithProcessRuntimeVersion(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(attrs...),
+		resource.WithFromEnv(),
+	)
+	if res == nil {
+		res = resource.NewSchemaless(attrs...)
+	}
+	return res, err
+}
+
+// buildVersion читает версию из build info, которую Go записывает в бинарник:
+// версия модуля (go install module@v1.2.3), иначе короткий хеш коммита (+"-dirty" при
+// незакоммиченных правках), иначе "dev". В Nix-сборке VCS недоступен - версию задает
+// OTEL_RESOURCE_ATTRIBUTES=service.version=...
+func buildVersion() (version, revision string, modified bool) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "dev", "", false
+	}
+
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = s.Value == "true"
+		}
+	}
+
+	switch {
+	case info.Main.Version != "" && info.Main.Version != "(devel)":
+		version = info.Main.Version
+	case revision != "":
+		version = revision
+		if len(version) > 12 {
+			version = version[:12]
+		}
+		if modified {
+			version += "-dirty"
+		}
+	default:
+		version = "dev"
+	}
+	return version, revision, modified
+}
@@ -0,0 +1,193 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Стратегии сэмплирования корневых спанов (telemetry.sample_strategy)
+const (
+	SampleAlways    = "always"    // Каждый трейс - для dev
+	SampleRatio     = "ratio"     // Доля трейсов sample_ratio (по trace_id: все сервисы решают одинаково)
+	SampleRateLimit = "ratelimit" // Не больше sample_rate трейсов в секунду
+)
+
+// newSampler собирает сэмплер из cfg:
+//   - спаны маршрутов sample_drop_routes не пишутся никогда;
+//   - решение вышестоящего сервиса (sampled во входящем traceparent) уважается - трейс не рвется между сервисами;
+//   - корневые спаны решает стратегия sample_strategy;
+//   - с sample_errors неотобранные спаны все равно записываются (RecordOnly), чтобы errorSpanProcessor
+//     мог отправить те, что завершились ошибкой.
+func newSampler(cfg Config) (sdktrace.Sampler, error) {
+	var root sdktrace.Sampler
+	switch cfg.SampleStrategy {
+	case SampleAlways, "":
+		root = sdktrace.AlwaysSample()
+	case SampleRatio:
+		root = sdktrace.TraceIDRatioBased(cfg.SampleRatio)
+	case SampleRateLimit:
+		root = newRateLimitSampler(cfg.SampleRate)
+	default:
+		return nil, fmt.Errorf("unknown sample strategy %q", cfg.SampleStrategy)
+	}
+
+	return &routeSampler{
+		next:            sdktrace.ParentBased(root),
+		drop:            cfg.SampleDropRoutes,
+		recordUnsampled: cfg.SampleErrors,
+	}, nil
+}
+
+// routeAttrs - атрибуты, в которых otelhttp передает путь запроса при старте спана
+var routeAttrs = []attribute.Key{"http.route", "url.path", "http.target"}
+
+// routeSampler отбрасывает спаны служебных маршрутов и включает запись неотобранных спанов для sample_errors
+type routeSampler struct {
+	next            sdktrace.Sampler
+	drop            []string
+	recordUnsampled bool
+}
+
+func (s *routeSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	if s.dropped(p) {
+		return sdktrace.SamplingResult{
+			Decision:   sdktrace.Drop,
+			Tracestate: trace.SpanContextFromContext(p.ParentContext).TraceState(),
+		}
+	}
+
+	res := s.next.ShouldSample(p)
+	if res.Decision == sdktrace.Drop && s.recordUnsampled {
+		res.Decision = sdktrace.RecordOnly
+	}
+	return res
+}
+
+func (s *routeSampler) Description() string {
+	return fmt.Sprintf("RouteSampler{drop=%v,errors=%t,%s}", s.drop, s.recordUnsampled, s.next.Description())
+}
+
+// dropped - путь спана или имя gRPC-спана (otelgrpc пишет его без ведущего слэша: grpc.health.v1.Health/Check)
+// совпадает с шаблоном: "/health" - точно, "/admin/" и "grpc.health.v1.Health/" - по префиксу
+func (s *routeSampler) dropped(p sdktrace.SamplingParameters) bool {
+	if len(s.drop) == 0 {
+		return false
+	}
+	if matchRoute(s.drop, p.Name) {
+		return true
+	}
+	for _, kv := range p.Attributes {
+		for _, key := range routeAttrs {
+			if kv.Key == key && matchRoute(s.drop, kv.Value.AsString()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchRoute(patterns []string, route string) bool {
+	if route == "" {
+		return false
+	}
+	for _, p := range patterns {
+		if route == p || (strings.HasSuffix(p, "/") && strings.HasPrefix(route, p)) {
+			return true
+		}
+	}
+	return false
+}
+
+// rateLimitSampler - token bucket: не больше rate трейсов в секунду, всплеск до burst.
+// burst не меньше 1: иначе при rate < 1 (один трейс в 10 секунд) корзина не набирала бы целый токен.
+type rateLimitSampler struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimitSampler(rate float64) *rateLimitSampler {
+	burst := max(rate, 1)
+	return &rateLimitSampler{rate: rate, burst: burst, tokens: burst, last: time.Now()}
+}
+
+func (s *rateLimitSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	res := sdktrace.SamplingResult{
+		Decision:   sdktrace.Drop,
+		Tracestate: trace.SpanContextFromContext(p.ParentContext).TraceState(),
+	}
+	if s.allow(time.Now()) {
+		res.Decision = sdktrace.RecordAndSample
+	}
+	return res
+}
+
+func (s *rateLimitSampler) allow(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens += now.Sub(s.last).Seconds() * s.rate
+	if s.tokens > s.burst {
+		s.tokens = s.burst
+	}
+	s.last = now
+
+	if s.tokens < 1 {
+		return false
+	}
+	s.tokens--
+	return true
+}
+
+func (s *rateLimitSampler) Description() string {
+	return fmt.Sprintf("RateLimitSampler{%g/s}", s.rate)
+}
+
+// errorSpanProcessor отправляет в next отобранные спаны и неотобранные, завершившиеся ошибкой (status Error):
+// ошибку видно в Tempo даже при sample_ratio=0.01. Родительские спаны такого трейса могли быть
+// не отобраны - тогда в Tempo будет только ветка с ошибкой.
+type errorSpanProcessor struct {
+	next sdktrace.SpanProcessor
+}
+
+func (p *errorSpanProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+}
+
+func (p *errorSpanProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	switch {
+	case s.SpanContext().IsSampled():
+		p.next.OnEnd(s)
+	case s.Status().Code == codes.Error:
+		p.next.OnEnd(sampledSpan{s})
+	}
+}
+
+func (p *errorSpanProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+func (p *errorSpanProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+// sampledSpan помечает спан отобранным: BatchSpanProcessor отбрасывает спаны без флага sampled
+type sampledSpan struct {
+	sdktrace.ReadOnlySpan
+}
+
+func (s sampledSpan) SpanContext() trace.SpanContext {
+	sc := s.ReadOnlySpan.SpanContext()
+	return sc.WithTraceFlags(sc.TraceFlags().WithSampled(true))
+}
@@ -0,0 +1,184 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRateLimitSamplerAllow(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+
+	tests := []struct {
+		name  string
+		rate  float64
+		calls []time.Duration // моменты вызовов allow от start
+		want  []bool
+	}{
+		{
+			name:  "burst up to rate",
+			rate:  2,
+			calls: []time.Duration{0, 0, 0},
+			want:  []bool{true, true, false},
+		},
+		{
+			name:  "refill over time",
+			rate:  2,
+			calls: []time.Duration{0, 0, 0, 500 * time.Millisecond, 500 * time.Millisecond},
+			want:  []bool{true, true, false, true, false},
+		},
+		{
+			name:  "bucket capped at rate",
+			rate:  2,
+			calls: []time.Duration{10 * time.Second, 10 * time.Second, 10 * time.Second},
+			want:  []bool{true, true, false},
+		},
+		{
+			name:  "rate below one",
+			rate:  0.1,
+			calls: []time.Duration{0, time.Second, 5 * time.Second, 10 * time.Second, 11 * time.Second, 30 * time.Second},
+			want:  []bool{true, false, false, true, false, true},
+		},
+		{
+			name:  "rate below one caps burst at one",
+			rate:  0.5,
+			calls: []time.Duration{time.Minute, time.Minute},
+			want:  []bool{true, false},
+		},
+		{
+			name:  "zero rate never samples after initial token",
+			rate:  0,
+			calls: []time.Duration{0, time.Hour},
+			want:  []bool{true, false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newRateLimitSampler(tt.rate)
+			s.last = start
+			for i, d := range tt.calls {
+				if got := s.allow(at(d)); got != tt.want[i] {
+					t.Errorf("call %d at +%v: allow = %t, want %t", i, d, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRouteSampler(t *testing.T) {
+	drop := []string{"/health", "/admin/", "grpc.health.v1.Health/"}
+
+	tests := []struct {
+		name            string
+		next            sdktrace.Sampler
+		recordUnsampled bool
+		span            string
+		attrs           []attribute.KeyValue
+		want            sdktrace.SamplingDecision
+	}{
+		{"regular route", sdktrace.AlwaysSample(), false, "GET /api", []attribute.KeyValue{attribute.String("url.path", "/api")}, sdktrace.RecordAndSample},
+		{"exact route by attribute", sdktrace.AlwaysSample(), false, "GET", []attribute.KeyValue{attribute.String("url.path", "/health")}, sdktrace.Drop},
+		{"exact route is not prefix", sdktrace.AlwaysSample(), false, "GET", []attribute.KeyValue{attribute.String("url.path", "/healthy")}, sdktrace.RecordAndSample},
+		{"prefix route", sdktrace.AlwaysSample(), false, "GET", []attribute.KeyValue{attribute.String("http.route", "/admin/log/level")}, sdktrace.Drop},
+		{"legacy target attribute", sdktrace.AlwaysSample(), false, "GET", []attribute.KeyValue{attribute.String("http.target", "/health")}, sdktrace.Drop},
+		{"grpc span name", sdktrace.AlwaysSample(), false, "grpc.health.v1.Health/Check", nil, sdktrace.Drop},
+		{"dropped even with sample_errors", sdktrace.NeverSample(), true, "grpc.health.v1.Health/Watch", nil, sdktrace.Drop},
+		{"unsampled recorded for sample_errors", sdktrace.NeverSample(), true, "GET /api", nil, sdktrace.RecordOnly},
+		{"unsampled dropped without sample_errors", sdktrace.NeverSample(), false, "GET /api", nil, sdktrace.Drop},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &routeSampler{next: tt.next, drop: drop, recordUnsampled: tt.recordUnsampled}
+			res := s.ShouldSample(sdktrace.SamplingParameters{
+				ParentContext: context.Background(),
+				Name:          tt.span,
+				Attributes:    tt.attrs,
+			})
+			if res.Decision != tt.want {
+				t.Errorf("decision = %v, want %v", res.Decision, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSamplerRespectsParent(t *testing.T) {
+	s, err := newSampler(Config{SampleStrategy: SampleRatio, SampleRatio: 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	res := s.ShouldSample(sdktrace.SamplingParameters{
+		ParentContext: trace.ContextWithRemoteSpanContext(context.Background(), parent),
+		TraceID:       parent.TraceID(),
+		Name:          "GET /api",
+	})
+	if res.Decision != sdktrace.RecordAndSample {
+		t.Errorf("decision = %v, want RecordAndSample from sampled parent", res.Decision)
+	}
+
+	if _, err := newSampler(Config{SampleStrategy: "sometimes"}); err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+}
+
+func TestErrorSpanProcessor(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(&routeSampler{next: sdktrace.NeverSample(), recordUnsampled: true}),
+		sdktrace.WithSpanProcessor(&errorSpanProcessor{next: rec}),
+	)
+	tracer := tp.Tracer("test")
+
+	_, ok := tracer.Start(context.Background(), "ok")
+	ok.End()
+
+	_, failed := tracer.Start(context.Background(), "failed")
+	failed.RecordError(errors.New("boom"))
+	failed.SetStatus(codes.Error, "boom")
+	failed.End()
+
+	ended := rec.Ended()
+	if len(ended) != 1 {
+		t.Fatalf("exported %d spans, want only the failed one", len(ended))
+	}
+	if ended[0].Name() != "failed" {
+		t.Errorf("exported span = %q, want failed", ended[0].Name())
+	}
+	if !ended[0].SpanContext().IsSampled() {
+		t.Error("failed span must be marked sampled for BatchSpanProcessor")
+	}
+}
+
+func TestErrorSpanProcessorPassesSampled(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithSpanProcessor(&errorSpanProcessor{next: rec}),
+	)
+
+	_, span := tp.Tracer("test").Start(context.Background(), "ok")
+	span.End()
+
+	if got := len(rec.Ended()); got != 1 {
+		t.Errorf("exported %d spans, want 1", got)
+	}
+	if got := len(rec.Started()); got != 1 {
+		t.Errorf("OnStart forwarded %d spans, want 1", got)
+	}
+}
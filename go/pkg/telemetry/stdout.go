@@ -0,0 +1,105 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// stdoutExporter печатает спаны деревом по трейсам - для отладки без Tempo:
+//
+//	🔭 trace 4bf92f3577b34da6a3ce929d0e0e4736
+//	   HTTP /hello  12.4ms  url.path=/hello
+//	     GreetUser  0.3ms
+//	     ❌ publish_chat_message  3.1ms  error="kafka unavailable"
+//
+// Спаны одного трейса, попавшие в разные пачки, печатаются отдельными деревьями.
+type stdoutExporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func newStdoutExporter(w io.Writer) *stdoutExporter {
+	return &stdoutExporter{w: w}
+}
+
+func (e *stdoutExporter) ExportSpans(_ context.Context, spans []sdktrace.ReadOnlySpan) error {
+	var b strings.Builder
+
+	// Трейсы - в порядке первого спана в пачке
+	var traces []trace.TraceID
+	byTrace := make(map[trace.TraceID][]sdktrace.ReadOnlySpan)
+	for _, s := range spans {
+		id := s.SpanContext().TraceID()
+		if _, ok := byTrace[id]; !ok {
+			traces = append(traces, id)
+		}
+		byTrace[id] = append(byTrace[id], s)
+	}
+
+	for _, id := range traces {
+		fmt.Fprintf(&b, "🔭 trace %s\n", id)
+
+		group := byTrace[id]
+		sort.Slice(group, func(i, j int) bool { return group[i].StartTime().Before(group[j].StartTime()) })
+
+		inBatch := make(map[trace.SpanID]bool, len(group))
+		children := make(map[trace.SpanID][]sdktrace.ReadOnlySpan)
+		for _, s := range group {
+			inBatch[s.SpanContext().SpanID()] = true
+		}
+		var roots []sdktrace.ReadOnlySpan
+		for _, s := range group {
+			parent := s.Parent().SpanID()
+			if s.Parent().IsValid() && inBatch[parent] {
+				children[parent] = append(children[parent], s)
+			} else {
+				roots = append(roots, s)
+			}
+		}
+
+		var print func(s sdktrace.ReadOnlySpan, depth int)
+		print = func(s sdktrace.ReadOnlySpan, depth int) {
+			writeSpanLine(&b, s, depth)
+			for _, c := range children[s.SpanContext().SpanID()] {
+				print(c, depth+1)
+			}
+		}
+		for _, r := range roots {
+			print(r, 1)
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err := io.WriteString(e.w, b.String())
+	return err
+}
+
+func (e *stdoutExporter) Shutdown(context.Context) error { return nil }
+
+// writeSpanLine - "   ❌ name  3.1ms  key=value ..." с отступом по глубине
+func writeSpanLine(b *strings.Builder, s sdktrace.ReadOnlySpan, depth int) {
+	b.WriteString(strings.Repeat("  ", depth+1))
+	if s.Status().Code == codes.Error {
+		b.WriteString("❌ ")
+	}
+	b.WriteString(s.Name())
+	fmt.Fprintf(b, "  %s", s.EndTime().Sub(s.StartTime()).Round(time.Microsecond))
+
+	if s.Status().Code == codes.Error && s.Status().Description != "" {
+		fmt.Fprintf(b, "  error=%q", s.Status().Description)
+	}
+	for _, kv := range s.Attributes() {
+		fmt.Fprintf(b, "  %s=%s", kv.Key, kv.Value.Emit())
+	}
+	b.WriteByte('\n')
+}
@@ -2,96 +2,190 @@ package telemetry
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
-	"runtime"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
 
-	"github.com/grafana/pyroscope-go"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	otelruntime "go.opentelemetry.io/contrib/instrumentation/runtime"
 	"go.opentelemetry.io/contrib/propagators/b3"
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
-	"go.opentelemetry.io/otel/exporters/prometheus"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
 	"go.opentelemetry.io/otel/log/global"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/log"
 	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/exemplar"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
-	"net/http"
 )
 
-// InitTracer инициализирует глобальный TracerProvider
-func InitTracer(ctx context.Context, serviceName string, collectorAddr string) (func(context.Context) error, error) {
-	res, err := resource.New(ctx,
-		resource.WithAttributes(
-			semconv.ServiceName(serviceName),
-			semconv.ServiceVersion("0.1.0"),
-		),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create resource: %w", err)
-	}
-
-	conn, err := grpc.DialContext(ctx, collectorAddr,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create gRPC connection to collector at %s: %w", collectorAddr, err)
-	}
+// Config - настройки telemetry. Поля повторяют config.TelemetryConfig (пакеты pkg не импортируют друг друга),
+// поэтому в main достаточно преобразования типов: telemetry.Config(cfg.Telemetry).
+type Config struct {
+	OtelEndpoint      string
+	PyroscopeEndpoint string
+	ServiceName       string
+
+	Traces          bool
+	Metrics         bool
+	Logs            bool
+	ShutdownTimeout time.Duration
+
+	SampleStrategy   string
+	SampleRatio      float64
+	SampleRate       float64
+	SampleDropRoutes []string
+	SampleErrors     bool
+
+	Exporter          string
+	OtelHeaders       string
+	OtelInsecure      bool
+	OtelCAFile        string
+	OtelCertFile      string
+	OtelKeyFile       string
+	ExportFile        string
+	ExportFileMaxSize int
+	ExportFileBackups int
+
+	ProfileTypes []string
+	PprofAddr    string
+	PprofToken   string
+}
 
-	traceExporter, err := otlptracegrpc.New(ctx, otlptracegrpc.WithGRPCConn(conn))
+// Shutdown останавливает все сигналы, поднятые Setup
+type Shutdown func(context.Context) error
+
+// Setup поднимает включенные в cfg сигналы: трейсы (экспортер cfg.Exporter, см. exporters.go),
+// логи (OTLP), метрики (Prometheus, /metrics), профилирование (Pyroscope, если задан pyroscope_endpoint,
+// и /debug/pprof на pprof_addr, см. profiler.go и pprof.go) - с общим ресурсом (см. resource.go)
+// и пропагатором W3C TraceContext + Baggage + B3.
+//
+// Shutdown не nil даже при ошибке: сигнал, который не поднялся, не мешает остальным, а ошибки
+// всех сигналов возвращаются вместе. Shutdown можно вызывать несколько раз - останавливает один раз,
+// сбрасывая буферы не дольше cfg.ShutdownTimeout.
+func Setup(ctx context.Context, cfg Config) (Shutdown, error) {
+	var errs []error
+
+	res, err := newResource(ctx, cfg.ServiceName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+		// Частичный ресурс (например, не определился хост) лучше, чем никакого
+		errs = append(errs, fmt.Errorf("failed to detect resource: %w", err))
 	}
 
-	tracerProvider := sdktrace.NewTracerProvider(
-		sdktrace.WithSampler(sdktrace.AlwaysSample()),
-		sdktrace.WithResource(res),
-		sdktrace.WithSpanProcessor(sdktrace.NewBatchSpanProcessor(traceExporter)),
-	)
-
-	otel.SetTracerProvider(tracerProvider)
-
 	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
 		propagation.TraceContext{},
 		propagation.Baggage{},
 		b3.New(),
 	))
 
-	return func(ctx context.Context) error {
-		if err := tracerProvider.Shutdown(ctx); err != nil {
-			return err
+	// Без обработчика SDK пишет ошибки экспорта в стандартный log без уровня, и недоступный
+	// коллектор легко не заметить. Повторы одной ошибки схлопывает сэмплирование логгера.
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		slog.Default().Error("❌ OpenTelemetry export error", "error", err)
+	}))
+
+	var shutdowns []func(context.Context) error
+	start := func(enabled bool, init func() (func(context.Context) error, error)) {
+		if !enabled {
+			return
 		}
-		return conn.Close()
-	}, nil
-}
+		// Сигнал может подняться частично (метрики без runtime-инструментации) - тогда его тоже останавливаем
+		stop, err := init()
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if stop != nil {
+			shutdowns = append(shutdowns, stop)
+		}
+	}
 
-// InitLogs инициализирует отправку логов в OTel Collector
-// Этот подход используется как fallback или для structured logs из кода
-func InitLogs(ctx context.Context, serviceName string, collectorAddr string) (func(context.Context) error, error) {
-	res, err := resource.New(ctx,
-		resource.WithAttributes(
-			semconv.ServiceName(serviceName),
-			semconv.ServiceVersion("0.1.0"),
-		),
+	start(cfg.Traces, func() (func(context.Context) error, error) {
+		return initTracer(ctx, res, cfg)
+	})
+	start(cfg.Metrics, func() (func(context.Context) error, error) {
+		return initMetrics(res)
+	})
+	start(cfg.Logs, func() (func(context.Context) error, error) {
+		return initLogs(ctx, res, cfg)
+	})
+	// Профайлер запускается и без pyroscope_endpoint: выборка mutex/block нужна и для /debug/pprof
+	start(true, func() (func(context.Context) error, error) {
+		return StopProfiler, StartProfiler(cfg)
+	})
+	start(cfg.PprofAddr != "", func() (func(context.Context) error, error) {
+		return startPprof(cfg)
+	})
+
+	timeout := cfg.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	var (
+		once        sync.Once
+		shutdownErr error
 	)
+	shutdown := func(ctx context.Context) error {
+		once.Do(func() {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			// В обратном порядке: логи о закрытии трейсинга еще успевают уйти
+			var errs []error
+			for i := len(shutdowns) - 1; i >= 0; i-- {
+				errs = append(errs, shutdowns[i](ctx))
+			}
+			shutdownErr = errors.Join(errs...)
+		})
+		return shutdownErr
+	}
+
+	return shutdown, errors.Join(errs...)
+}
+
+// initTracer инициализирует глобальный TracerProvider с сэмплированием из cfg (см. sampling.go)
+func initTracer(ctx context.Context, res *resource.Resource, cfg Config) (func(context.Context) error, error) {
+	sampler, err := newSampler(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create resource: %w", err)
+		return nil, err
 	}
 
-	conn, err := grpc.DialContext(ctx, collectorAddr,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	traceExporter, err := newTraceExporter(ctx, cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create gRPC connection for logs: %w", err)
+		return nil, fmt.Errorf("failed to create %s trace exporter: %w", cfg.Exporter, err)
+	}
+
+	var batchOpts []sdktrace.BatchSpanProcessorOption
+	if cfg.Exporter == ExporterStdout || cfg.Exporter == ExporterFile {
+		// Локальная отладка: трейс виден через секунду, а не через 5
+		batchOpts = append(batchOpts, sdktrace.WithBatchTimeout(time.Second))
+	}
+
+	var processor sdktrace.SpanProcessor = sdktrace.NewBatchSpanProcessor(traceExporter, batchOpts...)
+	if cfg.SampleErrors {
+		processor = &errorSpanProcessor{next: processor}
 	}
 
-	logExporter, err := otlploggrpc.New(ctx, otlploggrpc.WithGRPCConn(conn))
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sampler),
+		sdktrace.WithResource(res),
+		sdktrace.WithSpanProcessor(processor),
+	)
+
+	otel.SetTracerProvider(tracerProvider)
+
+	return tracerProvider.Shutdown, nil
+}
+
+// initLogs инициализирует отправку логов в OTel Collector.
+// pkg/logger дублирует в глобальный LoggerProvider каждую запись (см. logger/otel.go).
+func initLogs(ctx context.Context, res *resource.Resource, cfg Config) (func(context.Context) error, error) {
+	logExporter, err := newLogExporter(ctx, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create log exporter: %w", err)
 	}
@@ -103,68 +197,40 @@ func InitLogs(ctx context.Context, serviceName string, collectorAddr string) (fu
 
 	global.SetLoggerProvider(loggerProvider)
 
-	return func(ctx context.Context) error {
-		if err := loggerProvider.Shutdown(ctx); err != nil {
-			return err
-		}
-		return conn.Close()
-	}, nil
+	return loggerProvider.Shutdown, nil
 }
 
-// InitMetrics инициализирует Prometheus exporter и возвращает HTTP handler для /metrics
-func InitMetrics(serviceName string) (http.Handler, error) {
-	exporter, err := prometheus.New()
+// initMetrics инициализирует Prometheus exporter: метрики регистрируются в default registry,
+// /metrics отдает promhttp.Handler() (MetricsHandler). Там же коллекторы клиента Prometheus
+// (process_* - CPU, память, файловые дескрипторы процесса; go_*), а runtime-инструментация OTel
+// добавляет go.memory.*, go.goroutine.count, go.schedule.duration и т.д.
+func initMetrics(res *resource.Resource) (func(context.Context) error, error) {
+	exporter, err := otelprometheus.New()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create prometheus exporter: %w", err)
 	}
 
 	provider := metric.NewMeterProvider(
 		metric.WithReader(exporter),
-		metric.WithResource(resource.NewWithAttributes(
-			semconv.SchemaURL,
-			semconv.ServiceName(serviceName),
-		)),
+		metric.WithResource(res),
+		// Exemplar с trace_id/span_id из контекста измерения - только для отобранных спанов:
+		// из всплеска на графике можно перейти в трейс, который точно есть в Tempo
+		metric.WithExemplarFilter(exemplar.TraceBasedFilter),
 	)
 
 	otel.SetMeterProvider(provider)
 
-	return promhttp.Handler(), nil
-}
-
-// InitProfiler инициализирует Pyroscope профайлер
-func InitProfiler(serviceName string, serverAddr string) error {
-	if serverAddr == "" {
-		return nil
+	if err := otelruntime.Start(otelruntime.WithMeterProvider(provider)); err != nil {
+		return provider.Shutdown, fmt.Errorf("failed to start runtime metrics: %w", err)
 	}
 
-	hostname, _ := os.Hostname()
-
-	_, err := pyroscope.Start(pyroscope.Config{
-		ApplicationName: serviceName,
-		ServerAddress:   serverAddr,
-		Logger:          nil,
-		Tags: map[string]string{
-			"hostname": hostname,
-			"go_os":    runtime.GOOS,
-			"go_arch":  runtime.GOARCH,
-		},
-		ProfileTypes: []pyroscope.ProfileType{
-			pyroscope.ProfileCPU,
-			pyroscope.ProfileAllocObjects,
-			pyroscope.ProfileAllocSpace,
-			pyroscope.ProfileInuseObjects,
-			pyroscope.ProfileInuseSpace,
-			pyroscope.ProfileGoroutines,
-			pyroscope.ProfileMutexCount,
-			pyroscope.ProfileMutexDuration,
-			pyroscope.ProfileBlockCount,
-			pyroscope.ProfileBlockDuration,
-		},
-	})
-
-	if err != nil {
-		return fmt.Errorf("failed to start pyroscope profiler at %s: %w", serverAddr, err)
-	}
+	return provider.Shutdown, nil
+}
 
-	return nil
+// MetricsHandler - HTTP handler для /metrics. Отдает OpenMetrics, если скрейпер его запрашивает
+// (Accept: application/openmetrics-text): exemplars есть только в этом формате.
+func MetricsHandler() http.Handler {
+	return promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true}),
+	)
 }
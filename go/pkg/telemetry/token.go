@@ -0,0 +1,28 @@
+package telemetry
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// RequireToken пропускает запросы с заголовком Authorization: Bearer <token>; остальным - 401
+// с WWW-Authenticate для realm. Общий для служебных эндпоинтов: pprof (pprof_token) и /admin/* сервисов
+// (server.admin_token). Пустой токен выключает эндпоинты (404): закрывать их одной маршрутизацией
+// gateway ненадежно.
+func RequireToken(token, realm string, next http.Handler) http.Handler {
+	if token == "" {
+		slog.Default().Warn("⚠️ Endpoints disabled: token is empty", "realm", realm)
+		return http.NotFoundHandler()
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+realm+`"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -2,29 +2,46 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
-	"net"
 	"net/http"
 	"os"
-	"os/signal"
 	"path/filepath"
-	"syscall"
 
 	"chat/internal/application"
 	grpc_implementation "chat/internal/infrastructure/grpc"
 	http_implementation "chat/internal/infrastructure/http"
 	"chat/internal/infrastructure/queue"
+	"chat/pkg/app"
 	"chat/pkg/config"
+	apperrors "chat/pkg/errors"
+	"chat/pkg/flags"
+	"chat/pkg/health"
 	"chat/pkg/logger"
+	"chat/pkg/metrics"
 	"chat/pkg/telemetry"
 
-	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
-	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/propagation"
 	"google.golang.org/grpc"
 )
 
 func main() {
+	printConfig := flag.Bool("print-config", false, "print effective config with value sources and exit")
+	printSchema := flag.Bool("config-schema", false, "print JSON Schema of yaml/toml config files and exit")
+	printReference := flag.Bool("config-reference", false, "print markdown reference of config keys and exit")
+	flag.Parse()
+
+	// Схема и справочник не зависят от окружения: печатаем до загрузки конфигурации
+	if *printSchema {
+		_ = config.WriteSchema(os.Stdout)
+		return
+	}
+	if *printReference {
+		_ = config.WriteReference(os.Stdout)
+		return
+	}
+
 	// 1. Загрузка конфигурации
 	loader := config.NewLoader("CHAT")
 	if err := loader.Load(); err != nil {
@@ -33,11 +50,19 @@ func main() {
 
 	var cfg config.AppConfig
 	if err := loader.Unmarshal(&cfg); err != nil {
+		if *printConfig {
+			// Показываем конфиг и при ошибке валидации: видно, откуда пришло невалидное значение
+			_ = loader.PrintConfig(os.Stdout, &cfg)
+		}
 		log.Fatalf("Failed to unmarshal config: %v", err)
 	}
+	if *printConfig {
+		_ = loader.PrintConfig(os.Stdout, &cfg)
+		return
+	}
 
 	// 2. Логгер
-	logger.Init("chat-service", cfg.Log.Level)
+	logger.Init("chat-service", logger.Config(cfg.Log))
 
 	// 3. Статика - ИСПРАВЛЕНИЕ: сначала резолвим, потом преобразуем в абсолютный путь
 	resolvedStaticDir := resolveStaticDir(cfg.Server.StaticDir)
@@ -60,73 +85,98 @@ func main() {
 		logger.Info(context.Background(), "✅ remoteEntry.js found", "path", remoteEntryPath)
 	}
 
-	// 4. Трейсинг
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
-		propagation.TraceContext{},
-		propagation.Baggage{},
-	))
-
-	shutdownTracer, err := telemetry.InitTracer(context.Background(), "chat-service", cfg.Telemetry.OtelEndpoint)
+	// 4. Телеметрия: трейсы, метрики, логи в OTLP, профилирование (секция telemetry)
+	telemetryCfg := telemetry.Config(cfg.Telemetry)
+	telemetryCfg.ServiceName = "chat-service"
+	shutdownTelemetry, err := telemetry.Setup(context.Background(), telemetryCfg)
 	if err != nil {
-		logger.Error(context.Background(), "⚠️ Failed to init tracer", "error", err)
-		shutdownTracer = func(context.Context) error { return nil }
+		logger.Error(context.Background(), "⚠️ Telemetry partially initialized", "error", err)
 	}
-	defer func() { _ = shutdownTracer(context.Background()) }()
 
-	metricsHandler, err := telemetry.InitMetrics("chat-service")
-	if err != nil {
-		logger.Error(context.Background(), "Failed to init metrics", "error", err)
-	}
+	// Жизненный цикл: компоненты останавливаются в обратном порядке, телеметрия - последней
+	runner := app.New(cfg.Server.ShutdownTimeout)
+	runner.Add(app.Component{Name: "telemetry", Stop: shutdownTelemetry})
 
 	// 5. Infrastructure: Kafka Producer
 	brokers := cfg.Kafka.Brokers
 	if len(brokers) == 0 {
-		logger.Error(context.Background(), "❌ CHAT_KAFKA_BROKERS is required but not set")
-		os.Exit(1)
+		runner.Fail(errors.New("CHAT_KAFKA_BROKERS is required but not set"))
 	}
 	logger.Info(context.Background(), "📡 Kafka Brokers", "brokers", brokers)
 
-	kafkaProducer := queue.NewKafkaProducer(brokers, cfg.Kafka.Topic)
-	defer kafkaProducer.Close()
-
-	// 6. Application Layer
-	postMessageHandler := application.NewPostMessageHandler(kafkaProducer)
-
-	// 7. Presentation Layer: HTTP Server
-	httpServer := http_implementation.NewServer(&cfg, kafkaProducer)
-
-	http.Handle("/metrics", metricsHandler)
-
-	errChan := make(chan error, 1)
-	go func() {
-		logger.Info(context.Background(), "🚀 HTTP Server listening", "port", cfg.Server.HTTPPort)
-		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			errChan <- err
+	kafkaProducer := queue.NewKafkaProducer(cfg.Kafka)
+	// Останавливается после серверов: принятые сообщения успевают уйти в Kafka
+	runner.Add(app.Component{
+		Name: "kafka-producer",
+		Stop: func(context.Context) error { return kafkaProducer.Close() },
+	})
+
+	// Проверки состояния: /livez, /readyz и grpc.health.v1 (пробы k8s, health check Envoy).
+	// Без брокера сообщения не принимаются - под выводится из балансировки.
+	probes := health.New(cfg.Server.HealthTimeout, cfg.Server.HealthCacheTTL, cfg.Server.ShutdownDrain)
+	probes.Add("kafka", health.Dial(brokers...))
+	if endpoint := telemetryCfg.CollectorEndpoint(); endpoint != "" {
+		probes.Add("otel-collector", health.Dial(endpoint), health.Optional())
+	}
+	probes.Add("static", health.File(remoteEntryPath))
+
+	// Hot reload: log.level, профилирование и параметры Kafka меняются без рестарта пода
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	if err := loader.Watch(watchCtx, func(old, new config.AppConfig) {
+		if old.Log.Level != new.Log.Level {
+			logger.SetLevel(new.Log.Level)
+		}
+		profilerCfg := telemetry.Config(new.Telemetry)
+		profilerCfg.ServiceName = "chat-service"
+		if err := telemetry.StartProfiler(profilerCfg); err != nil {
+			logger.Error(context.Background(), "Failed to reload profiler", "error", err)
+		}
+		if len(new.Kafka.Brokers) > 0 {
+			kafkaProducer.Reconfigure(new.Kafka)
 		}
-	}()
+	}); err != nil {
+		logger.Warn(context.Background(), "⚠️ Config hot reload disabled", "error", err)
+	}
 
-	// 8. Presentation Layer: gRPC Server
-	lis, err := net.Listen("tcp", ":"+cfg.Server.GRPCPort)
+	// 6. Application Layer: фич-флаги (configs/flags.yaml) перечитываются без рестарта
+	featureFlags, err := flags.New(filepath.Join(loader.Dir(), "flags.yaml"), loader.Env())
 	if err != nil {
-		logger.Error(context.Background(), "Failed to listen gRPC", "error", err)
-		return
+		runner.Fail(fmt.Errorf("failed to load feature flags: %w", err))
+	}
+	if err := featureFlags.Watch(watchCtx); err != nil {
+		logger.Warn(context.Background(), "⚠️ Feature flags hot reload disabled", "error", err)
 	}
 
-	grpcServer := grpc_implementation.NewServer(postMessageHandler, grpc.StatsHandler(otelgrpc.NewServerHandler()))
-	go func() {
-		if err := grpcServer.Serve(lis); err != nil {
-			errChan <- err
-		}
-	}()
+	// Бизнес-метрики (pkg/metrics): без них сервис работает как раньше
+	chatMetrics, err := metrics.NewChat()
+	if err != nil {
+		logger.Warn(context.Background(), "⚠️ Business metrics disabled", "error", err)
+	}
 
-	// Graceful Shutdown
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	postMessageHandler := application.NewPostMessageHandler(kafkaProducer, featureFlags, chatMetrics)
 
-	logger.Info(context.Background(), "🛑 Shutting down server...")
-	grpcServer.GracefulStop()
-	httpServer.Shutdown(context.Background())
+	// 7. Presentation Layer: HTTP Server
+	admin := http.NewServeMux()
+	admin.Handle("/admin/config", loader.Handler())
+	admin.Handle("/admin/log/level", logger.LevelHandler())
+	admin.Handle("/admin/flags", featureFlags.Handler())
+
+	httpServer := http_implementation.NewServer(&cfg, postMessageHandler, admin, probes)
+	runner.Add(app.HTTP("0.0.0.0:"+cfg.Server.HTTPPort, httpServer))
+
+	// 8. Presentation Layer: gRPC Server
+	grpcServer := grpc_implementation.NewServer(postMessageHandler,
+		telemetry.GRPCServerOption(),
+		grpc.ChainUnaryInterceptor(logger.UnaryServerInterceptor(), telemetry.ProfileUnaryInterceptor(), apperrors.UnaryServerInterceptor()),
+	)
+	probes.RegisterGRPC(grpcServer)
+	runner.Add(app.GRPC(":"+cfg.Server.GRPCPort, grpcServer))
+	// Последним: при остановке первым снимает готовность, пока серверы еще обслуживают запросы
+	runner.Add(app.Component{Name: "health", Run: probes.Run, Stop: probes.Shutdown})
+
+	// 9. Запуск до SIGTERM или первой ошибки сервера; при ошибке - выход с кодом 1
+	runner.Main()
 }
 
 func resolveStaticDir(configPath string) string {
@@ -3,7 +3,9 @@ package application
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 
 	"chat/internal/domain"
 )
@@ -15,6 +17,29 @@ type EventBus interface {
 	Publish(ctx context.Context, key string, payload []byte) error
 }
 
+// FeatureFlags - порт для фич-флагов (реализация: pkg/flags).
+type FeatureFlags interface {
+	Bool(ctx context.Context, key, userID string) bool
+}
+
+// Metrics - порт бизнес-метрик (реализация: pkg/metrics.Chat).
+type Metrics interface {
+	MessagePosted(ctx context.Context, outcome string, size int)
+}
+
+// Исходы Handle для Metrics.MessagePosted, совпадают с metrics.Outcome*
+const (
+	OutcomePublished = "published"
+	OutcomeRejected  = "rejected"
+	OutcomeFailed    = "failed"
+)
+
+// ErrPublishFailed - событие не записано в EventBus; команду можно повторить
+var ErrPublishFailed = errors.New("failed to publish event")
+
+// TrimContentFlag - обрезка пробелов по краям сообщения; сообщение из одних пробелов отклоняется.
+const TrimContentFlag = "chat.trim-content"
+
 // --- CQRS: WRITE SIDE (Commands) ---
 
 // PostMessageCommand - команда на отправку сообщения.
@@ -26,19 +51,29 @@ type PostMessageCommand struct {
 // PostMessageHandler - обработчик команды.
 type PostMessageHandler struct {
 	eventBus EventBus
+	flags    FeatureFlags
+	metrics  Metrics
 }
 
-func NewPostMessageHandler(eventBus EventBus) *PostMessageHandler {
+func NewPostMessageHandler(eventBus EventBus, flags FeatureFlags, metrics Metrics) *PostMessageHandler {
 	return &PostMessageHandler{
 		eventBus: eventBus,
+		flags:    flags,
+		metrics:  metrics,
 	}
 }
 
 // Handle выполняет бизнес-логику и публикует события.
 func (h *PostMessageHandler) Handle(ctx context.Context, cmd PostMessageCommand) (string, error) {
+	content := cmd.Content
+	if h.flags.Bool(ctx, TrimContentFlag, cmd.AuthorID) {
+		content = strings.TrimSpace(content)
+	}
+
 	// 1. Domain Logic: Создание агрегата
-	_, events, err := domain.NewMessage(cmd.AuthorID, cmd.Content)
+	_, events, err := domain.NewMessage(cmd.AuthorID, content)
 	if err != nil {
+		h.metrics.MessagePosted(ctx, OutcomeRejected, len(content))
 		return "", fmt.Errorf("domain error: %w", err)
 	}
 
@@ -47,14 +82,17 @@ func (h *PostMessageHandler) Handle(ctx context.Context, cmd PostMessageCommand)
 	for _, event := range events {
 		payload, err := json.Marshal(event)
 		if err != nil {
+			h.metrics.MessagePosted(ctx, OutcomeFailed, len(content))
 			return "", fmt.Errorf("failed to marshal event: %w", err)
 		}
 
 		// Используем EventName как ключ (Topic/Key)
 		if err := h.eventBus.Publish(ctx, event.EventName(), payload); err != nil {
-			return "", fmt.Errorf("failed to publish event: %w", err)
+			h.metrics.MessagePosted(ctx, OutcomeFailed, len(content))
+			return "", fmt.Errorf("%w: %w", ErrPublishFailed, err)
 		}
 	}
+	h.metrics.MessagePosted(ctx, OutcomePublished, len(content))
 
 	return "Message processed async", nil
 }
@@ -3,5 +3,7 @@ package domain
 import "errors"
 
 var (
-	ErrEmptyName = errors.New("name cannot be empty")
+	ErrEmptyName    = errors.New("name cannot be empty")
+	ErrEmptyContent = errors.New("message content cannot be empty")
+	ErrEmptyAuthor  = errors.New("authorID cannot be empty")
 )
@@ -1,7 +1,6 @@
 package domain
 
 import (
-	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -44,10 +43,10 @@ func (e MessagePostedEvent) EventName() string {
 // Создает агрегат и возвращает несохраненные события.
 func NewMessage(authorID, content string) (*Message, []DomainEvent, error) {
 	if content == "" {
-		return nil, nil, fmt.Errorf("message content cannot be empty")
+		return nil, nil, ErrEmptyContent
 	}
 	if authorID == "" {
-		return nil, nil, fmt.Errorf("authorID cannot be empty")
+		return nil, nil, ErrEmptyAuthor
 	}
 
 	id := uuid.New().String()
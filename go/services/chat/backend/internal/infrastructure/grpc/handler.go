@@ -4,13 +4,13 @@ import (
 	"context"
 
 	"chat/internal/application"
+	"chat/internal/domain"
+	apperrors "chat/pkg/errors"
 	// Предполагаем, что используется helloworld.proto как временный контракт
 	// так как в дереве файлов нет chat.proto
 	pb "chat/pkg/proto/helloworld"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
 type Server struct {
@@ -34,7 +34,8 @@ func NewServer(postMessageHandler *application.PostMessageHandler, opts ...grpc.
 // Мы адаптируем запрос "SayHello" в команду "PostMessage".
 func (s *Server) SayHello(ctx context.Context, req *pb.HelloRequest) (*pb.HelloReply, error) {
 	if req.Name == "" {
-		return nil, status.Error(codes.InvalidArgument, "Name (content) is required")
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "Name (content) is required").
+			WithDetail("name", "must not be empty")
 	}
 
 	// Адаптер: Превращаем DTO в Domain Command
@@ -48,7 +49,11 @@ func (s *Server) SayHello(ctx context.Context, req *pb.HelloRequest) (*pb.HelloR
 	// Вызов Application Layer (CQRS Command Side)
 	resultMsg, err := s.postMessageHandler.Handle(ctx, cmd)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to post message: %v", err)
+		// Коды и errdetails проставляет apperrors.UnaryServerInterceptor
+		return nil, apperrors.Classify(err,
+			apperrors.On(apperrors.CodeInvalidArgument, domain.ErrEmptyContent, domain.ErrEmptyAuthor),
+			apperrors.On(apperrors.CodeUnavailable, application.ErrPublishFailed),
+		)
 	}
 
 	return &pb.HelloReply{
@@ -4,18 +4,21 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
-	"time"
 
 	"chat/internal/application"
+	"chat/internal/domain"
 	"chat/internal/middleware"
 	"chat/pkg/config"
+	apperrors "chat/pkg/errors"
+	"chat/pkg/health"
 	"chat/pkg/logger"
+	"chat/pkg/telemetry"
 
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -24,9 +27,9 @@ import (
 )
 
 type Server struct {
-	server   *http.Server
-	eventBus application.EventBus
-	config   *config.AppConfig
+	server             *http.Server
+	postMessageHandler *application.PostMessageHandler
+	config             *config.AppConfig
 }
 
 type MessageDTO struct {
@@ -34,19 +37,25 @@ type MessageDTO struct {
 	Text    string            `json:"text"`
 }
 
-func NewServer(cfg *config.AppConfig, eventBus application.EventBus) *Server {
+// admin обслуживает /admin/* (конфигурация, фич-флаги, уровень логов), probes - /livez и /readyz; собираются в main.
+// /admin/* закрыт токеном server.admin_token (telemetry.RequireToken)
+func NewServer(cfg *config.AppConfig, postMessageHandler *application.PostMessageHandler, admin http.Handler, probes *health.Registry) *Server {
 	mux := http.NewServeMux()
 
 	s := &Server{
-		eventBus: eventBus,
-		config:   cfg,
+		postMessageHandler: postMessageHandler,
+		config:             cfg,
 	}
 
 	// ВАЖНО: Регистрируем API endpoints ПЕРЕД static handler
-	mux.Handle("/metrics", promhttp.Handler())
-	mux.Handle("/health", http.HandlerFunc(s.HandleHealth))
-
-	handlePostMessage := http.HandlerFunc(s.HandlePostMessage)
+	mux.Handle("/metrics", telemetry.MetricsHandler())
+	mux.Handle("/livez", probes.LiveHandler())
+	mux.Handle("/readyz", probes.ReadyHandler())
+	mux.Handle("/health", probes.ReadyHandler()) // Прежний путь проверок
+	mux.Handle("/admin/", telemetry.RequireToken(cfg.Server.AdminToken, "admin", admin))
+
+	// Ошибки обработчика отдаются как application/problem+json
+	handlePostMessage := apperrors.HandlerFunc(s.HandlePostMessage)
 	mux.Handle("/messages", otelhttp.NewHandler(handlePostMessage, "POST /messages"))
 
 	// Static Files Handler - регистрируем ПОСЛЕДНИМ чтобы не перехватывал API
@@ -114,7 +123,7 @@ func NewServer(cfg *config.AppConfig, eventBus application.EventBus) *Server {
 		}))
 	}
 
-	handler := middleware.CORS(mux)
+	handler := middleware.CORS(telemetry.ProfileHTTPMiddleware(mux, logger.HTTPMiddleware(mux)))
 
 	s.server = &http.Server{
 		Addr:    "0.0.0.0:" + cfg.Server.HTTPPort,
@@ -128,10 +137,10 @@ func (s *Server) SetAddr(addr string) {
 	s.server.Addr = addr
 }
 
-func (s *Server) HandlePostMessage(w http.ResponseWriter, r *http.Request) {
+func (s *Server) HandlePostMessage(w http.ResponseWriter, r *http.Request) error {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
+		return nil
 	}
 
 	ctx := r.Context()
@@ -149,24 +158,22 @@ func (s *Server) HandlePostMessage(w http.ResponseWriter, r *http.Request) {
 
 	var msg MessageDTO
 	if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
-		return
+		return apperrors.Wrap(err, apperrors.CodeInvalidArgument, "Invalid JSON")
 	}
 
-	logger.Info(spanCtx, "📩 Message received via HTTP", "text", msg.Text)
-
-	eventPayload, _ := json.Marshal(map[string]interface{}{
-		"text":   msg.Text,
-		"sender": "http_user",
-		"ts":     time.Now(),
-	})
+	logger.Info(spanCtx, "📩 Message received via HTTP", "text", logger.Sensitive(msg.Text), "length", len(msg.Text))
 
-	err := s.eventBus.Publish(spanCtx, "chat.message_posted", eventPayload)
-	if err != nil {
+	// Та же команда, что и у gRPC SayHello: валидация домена, событие MessagePosted, бизнес-метрики
+	cmd := application.PostMessageCommand{
+		AuthorID: "http_user",
+		Content:  msg.Text,
+	}
+	if _, err := s.postMessageHandler.Handle(spanCtx, cmd); err != nil {
 		span.RecordError(err)
-		logger.Error(spanCtx, "Failed to publish to EventBus", "error", err)
-		http.Error(w, "Failed to process message", http.StatusInternalServerError)
-		return
+		return apperrors.Classify(err,
+			apperrors.On(apperrors.CodeInvalidArgument, domain.ErrEmptyContent, domain.ErrEmptyAuthor),
+			apperrors.On(apperrors.CodeUnavailable, application.ErrPublishFailed),
+		)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -176,15 +183,12 @@ func (s *Server) HandlePostMessage(w http.ResponseWriter, r *http.Request) {
 		"status": "queued",
 		"event":  "chat.message_posted",
 	})
+	return nil
 }
 
-func (s *Server) HandleHealth(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"status": "healthy"})
-}
-
-func (s *Server) ListenAndServe() error {
-	return s.server.ListenAndServe()
+// Serve обслуживает запросы на lis; порт занимает app.HTTP
+func (s *Server) Serve(lis net.Listener) error {
+	return s.server.Serve(lis)
 }
 
 func (s *Server) Shutdown(ctx context.Context) error {
@@ -2,8 +2,12 @@ package queue
 
 import (
 	"context"
+	"errors"
+	"reflect"
+	"sync"
 	"time"
 
+	"chat/pkg/config"
 	"chat/pkg/logger"
 
 	"github.com/segmentio/kafka-go"
@@ -45,39 +49,77 @@ func (c *kafkaHeaderCarrier) Keys() []string {
 // --- Producer ---
 
 type KafkaProducer struct {
-	writer *kafka.Writer
-	tracer trace.Tracer
-	topic  string
+	// mu защищает writer и cfg: Reconfigure подменяет writer на лету
+	mu      sync.RWMutex
+	writer  *kafka.Writer
+	cfg     config.KafkaConfig
+	tracer  trace.Tracer
+	metrics *producerMetrics
 }
 
-func NewKafkaProducer(brokers []string, topic string) *KafkaProducer {
-	w := &kafka.Writer{
-		Addr:                   kafka.TCP(brokers...),
-		Topic:                  topic,
+func NewKafkaProducer(cfg config.KafkaConfig) *KafkaProducer {
+	p := &KafkaProducer{
+		writer: newWriter(cfg),
+		cfg:    cfg,
+		tracer: otel.Tracer("kafka-producer"),
+	}
+
+	metrics, err := newProducerMetrics(p.stats)
+	if err != nil {
+		// Без метрик продьюсер работает как раньше
+		logger.Warn(context.Background(), "⚠️ [Kafka] Producer metrics disabled", "error", err)
+	}
+	p.metrics = metrics
+	return p
+}
+
+func newWriter(cfg config.KafkaConfig) *kafka.Writer {
+	return &kafka.Writer{
+		Addr:                   kafka.TCP(cfg.Brokers...),
+		Topic:                  cfg.Topic,
 		Balancer:               &kafka.LeastBytes{},
 		AllowAutoTopicCreation: true,
 		Async:                  false,
 		BatchTimeout:           10 * time.Millisecond,
-		WriteTimeout:           10 * time.Second,
+		WriteTimeout:           cfg.WriteTimeout,
 		RequiredAcks:           kafka.RequireOne,
 	}
-	return &KafkaProducer{
-		writer: w,
-		topic:  topic,
-		tracer: otel.Tracer("kafka-producer"),
+}
+
+// Reconfigure пересоздает writer с новыми брокерами/топиком/таймаутами (hot reload конфига).
+// Публикации, которые уже идут, дописываются старым writer'ом до его закрытия.
+func (p *KafkaProducer) Reconfigure(cfg config.KafkaConfig) {
+	p.mu.Lock()
+	if reflect.DeepEqual(p.cfg, cfg) {
+		p.mu.Unlock()
+		return
+	}
+	old := p.writer
+	p.writer = newWriter(cfg)
+	p.cfg = cfg
+	p.mu.Unlock()
+
+	logger.Info(context.Background(), "🔄 [Kafka] Producer reconfigured", "brokers", cfg.Brokers, "topic", cfg.Topic, "write_timeout", cfg.WriteTimeout)
+
+	if err := old.Close(); err != nil {
+		logger.Error(context.Background(), "❌ [Kafka] Failed to close previous writer", "error", err)
 	}
 }
 
 func (p *KafkaProducer) Publish(ctx context.Context, key string, payload []byte) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
 	ctx, span := p.tracer.Start(ctx, key+" send",
 		trace.WithSpanKind(trace.SpanKindProducer),
 		trace.WithAttributes(
 			semconv.MessagingSystem("kafka"),
-			semconv.MessagingDestinationName(p.topic),
+			semconv.MessagingDestinationName(p.cfg.Topic),
 			semconv.MessagingKafkaMessageKey(key),
 		),
 	)
 	defer span.End()
+	ctx = logger.With(ctx, logger.KeyEventType, key)
 
 	msg := kafka.Message{
 		Key:   []byte(key),
@@ -91,14 +133,25 @@ func (p *KafkaProducer) Publish(ctx context.Context, key string, payload []byte)
 
 	err := p.writer.WriteMessages(ctx, msg)
 	if err != nil {
+		p.metrics.failed(ctx, p.cfg.Topic)
 		span.RecordError(err)
 		logger.Error(ctx, "❌ [Kafka] Failed to publish", "error", err)
 		return err
 	}
-	logger.Info(ctx, "📤 [Kafka] Event Published", "event_type", key, "size", len(payload))
+	p.metrics.published(ctx, p.cfg.Topic, len(payload))
+	logger.Info(ctx, "📤 [Kafka] Event Published", "size", len(payload))
 	return nil
 }
 
+// stats - статистика текущего writer'а для producerMetrics
+func (p *KafkaProducer) stats() (kafka.WriterStats, string) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.writer.Stats(), p.cfg.Topic
+}
+
 func (p *KafkaProducer) Close() error {
-	return p.writer.Close()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return errors.Join(p.metrics.close(), p.writer.Close())
 }
@@ -0,0 +1,121 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+// producerMetrics - метрики продьюсера: сообщения, байты и ошибки считаются в Publish,
+// длительности батчей и записи снимаются из kafka.Writer.Stats() при каждом сборе (scrape /metrics).
+type producerMetrics struct {
+	messages metric.Int64Counter
+	bytes    metric.Int64Counter
+	errors   metric.Int64Counter
+
+	registration metric.Registration
+}
+
+// newProducerMetrics регистрирует метрики kafka.producer.*; stats возвращает текущий writer
+// (Reconfigure его подменяет). Writer.Stats() сбрасывает сводки при каждом вызове:
+// avg/max - за интервал между сборами.
+func newProducerMetrics(stats func() (kafka.WriterStats, string)) (*producerMetrics, error) {
+	meter := otel.Meter("kafka-producer")
+
+	m := &producerMetrics{}
+	var err error
+	if m.messages, err = meter.Int64Counter("kafka.producer.messages",
+		metric.WithDescription("Messages successfully written to Kafka"),
+		metric.WithUnit("{message}"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create kafka metric: %w", err)
+	}
+	if m.bytes, err = meter.Int64Counter("kafka.producer.message.size",
+		metric.WithDescription("Payload bytes successfully written to Kafka"),
+		metric.WithUnit("By"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create kafka metric: %w", err)
+	}
+	if m.errors, err = meter.Int64Counter("kafka.producer.errors",
+		metric.WithDescription("Failed Kafka writes"),
+		metric.WithUnit("{error}"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create kafka metric: %w", err)
+	}
+
+	batchTime, err := meter.Float64ObservableGauge("kafka.producer.batch.duration",
+		metric.WithDescription("Time to fill and send a batch since the previous collection (stat=avg|max)"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kafka metric: %w", err)
+	}
+	writeTime, err := meter.Float64ObservableGauge("kafka.producer.write.duration",
+		metric.WithDescription("Time of a produce request to the broker since the previous collection (stat=avg|max)"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kafka metric: %w", err)
+	}
+	batchSize, err := meter.Int64ObservableGauge("kafka.producer.batch.messages",
+		metric.WithDescription("Messages per batch since the previous collection (stat=avg|max)"),
+		metric.WithUnit("{message}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kafka metric: %w", err)
+	}
+
+	m.registration, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		s, topic := stats()
+		avgStat := metric.WithAttributes(append(attrs(topic), attribute.String("stat", "avg"))...)
+		maxStat := metric.WithAttributes(append(attrs(topic), attribute.String("stat", "max"))...)
+
+		o.ObserveFloat64(batchTime, s.BatchTime.Avg.Seconds(), avgStat)
+		o.ObserveFloat64(batchTime, s.BatchTime.Max.Seconds(), maxStat)
+		o.ObserveFloat64(writeTime, s.WriteTime.Avg.Seconds(), avgStat)
+		o.ObserveFloat64(writeTime, s.WriteTime.Max.Seconds(), maxStat)
+		o.ObserveInt64(batchSize, s.BatchSize.Avg, avgStat)
+		o.ObserveInt64(batchSize, s.BatchSize.Max, maxStat)
+		return nil
+	}, batchTime, writeTime, batchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register kafka metrics: %w", err)
+	}
+	return m, nil
+}
+
+func (m *producerMetrics) published(ctx context.Context, topic string, size int) {
+	if m == nil {
+		return
+	}
+	dest := metric.WithAttributes(attrs(topic)...)
+	m.messages.Add(ctx, 1, dest)
+	m.bytes.Add(ctx, int64(size), dest)
+}
+
+func (m *producerMetrics) failed(ctx context.Context, topic string) {
+	if m == nil {
+		return
+	}
+	m.errors.Add(ctx, 1, metric.WithAttributes(attrs(topic)...))
+}
+
+func (m *producerMetrics) close() error {
+	if m == nil {
+		return nil
+	}
+	return m.registration.Unregister()
+}
+
+// attrs - атрибуты назначения по OTel semconv messaging.*
+func attrs(topic string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		semconv.MessagingSystem("kafka"),
+		semconv.MessagingDestinationName(topic),
+	}
+}
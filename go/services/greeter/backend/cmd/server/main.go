@@ -2,140 +2,139 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
-	"net"
 	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
+	"path/filepath"
 
 	"greeter/internal/application"
-	grpcHandler "greeter/internal/infrastructure/grpc"
-	httpHandler "greeter/internal/infrastructure/http"
+	grpc_handler "greeter/internal/infrastructure/grpc"
+	http_handler "greeter/internal/infrastructure/http"
+	"greeter/pkg/app"
 	"greeter/pkg/config"
+	apperrors "greeter/pkg/errors"
+	"greeter/pkg/health"
 	"greeter/pkg/logger"
+	pb "greeter/pkg/proto/helloworld"
 	"greeter/pkg/telemetry"
 
-	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
 )
 
 func main() {
-	// Загружаем конфигурацию
-	loader := config.NewLoader()
-
-	// Устанавливаем дефолтные значения
-	loader.SetDefault("GREETER_HTTP_PORT", "8081")
-	loader.SetDefault("GREETER_GRPC_PORT", "50051")
-	loader.SetDefault("LOG_LEVEL", "info")
-	loader.SetDefault("LOG_FORMAT", "text")
-	loader.SetDefault("OTEL_EXPORTER_OTLP_ENDPOINT", "127.0.0.1:4317")
+	printConfig := flag.Bool("print-config", false, "print effective config with value sources and exit")
+	printSchema := flag.Bool("config-schema", false, "print JSON Schema of yaml/toml config files and exit")
+	printReference := flag.Bool("config-reference", false, "print markdown reference of config keys and exit")
+	flag.Parse()
+
+	// Схема и справочник не зависят от окружения: печатаем до загрузки конфигурации
+	if *printSchema {
+		_ = config.WriteSchema(os.Stdout)
+		return
+	}
+	if *printReference {
+		_ = config.WriteReference(os.Stdout)
+		return
+	}
 
+	// 1. Config (Prefix: GREETER)
+	loader := config.NewLoader("GREETER")
 	if err := loader.Load(); err != nil {
 		log.Fatalf("❌ Failed to load config: %v", err)
 	}
 
-	v := loader.GetViper()
-
-	// Валидация критичных параметров
-	validator := config.NewValidator()
-	httpPort := v.GetString("GREETER_HTTP_PORT")
-	grpcPort := v.GetString("GREETER_GRPC_PORT")
-
-	if err := validator.ValidatePort(httpPort); err != nil {
-		log.Fatalf("❌ Invalid HTTP port: %v", err)
+	var cfg config.AppConfig
+	if err := loader.Unmarshal(&cfg); err != nil {
+		if *printConfig {
+			// Показываем конфиг и при ошибке валидации: видно, откуда пришло невалидное значение
+			_ = loader.PrintConfig(os.Stdout, &cfg)
+		}
+		log.Fatalf("❌ Failed to unmarshal config: %v", err)
 	}
-	if err := validator.ValidatePort(grpcPort); err != nil {
-		log.Fatalf("❌ Invalid gRPC port: %v", err)
+	if *printConfig {
+		_ = loader.PrintConfig(os.Stdout, &cfg)
+		return
 	}
 
-	logLevel := v.GetString("LOG_LEVEL")
-	if err := validator.ValidateOneOf(logLevel, []string{"debug", "info", "warn", "error"}, "log level"); err != nil {
-		log.Fatalf("❌ Invalid log level: %v", err)
+	serviceName := cfg.Telemetry.ServiceName
+	if serviceName == "" {
+		serviceName = "greeter-service"
 	}
 
-	// Инициализация логгера
-	logger.Init("greeter-service", logLevel)
+	logger.Init(serviceName, logger.Config(cfg.Log))
 	ctx := context.Background()
-	logger.Info(ctx, "🚀 Starting Greeter Service",
-		"env", v.GetString("APP_ENV"),
-		"http_port", httpPort,
-		"grpc_port", grpcPort)
-
-	// Инициализация трейсинга
-	otelCollector := v.GetString("OTEL_EXPORTER_OTLP_ENDPOINT")
-	shutdownTracer, err := telemetry.InitTracer(ctx, "greeter-service", otelCollector)
+	logger.Info(ctx, "🚀 Logger initialized", "level", cfg.Log.Level)
+
+	// 2. Telemetry: трейсы, метрики, логи в OTLP, профилирование (секция telemetry)
+	telemetryCfg := telemetry.Config(cfg.Telemetry)
+	telemetryCfg.ServiceName = serviceName
+	shutdownTelemetry, err := telemetry.Setup(context.Background(), telemetryCfg)
 	if err != nil {
-		logger.Error(ctx, "Failed to init tracer", "error", err)
-	} else {
-		defer func() {
-			if err := shutdownTracer(ctx); err != nil {
-				logger.Error(ctx, "Failed to shutdown tracer", "error", err)
-			}
-		}()
-		logger.Info(ctx, "✅ Tracing initialized", "collector", otelCollector)
+		logger.Error(context.Background(), "⚠️ Telemetry partially initialized", "error", err)
 	}
 
-	// Создаём use case и серверы
-	greeterUseCase := application.NewGreeterUseCase()
+	// Жизненный цикл: компоненты останавливаются в обратном порядке, телеметрия - последней
+	runner := app.New(cfg.Server.ShutdownTimeout)
+	runner.Add(app.Component{Name: "telemetry", Stop: shutdownTelemetry})
 
-	grpcOpts := []grpc.ServerOption{
-		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+	// Проверки состояния: /livez, /readyz и grpc.health.v1 (пробы k8s, health check Envoy)
+	probes := health.New(cfg.Server.HealthTimeout, cfg.Server.HealthCacheTTL, cfg.Server.ShutdownDrain)
+	if endpoint := telemetryCfg.CollectorEndpoint(); endpoint != "" {
+		probes.Add("otel-collector", health.Dial(endpoint), health.Optional())
 	}
-	grpcServer := grpcHandler.NewServer(greeterUseCase, grpcOpts...)
-
-	// Для HTTP сервера создаём минимальную конфигурацию
-	cfg := &config.AppConfig{
-		Server: config.ServerConfig{
-			HTTPPort:  httpPort,
-			GRPCPort:  grpcPort,
-			StaticDir: v.GetString("SHELL_STATIC_DIR"),
-		},
-		Log: config.LogConfig{
-			Level:  logLevel,
-			Format: v.GetString("LOG_FORMAT"),
-		},
+	if cfg.Server.StaticDir != "" {
+		probes.Add("static", health.File(filepath.Join(cfg.Server.StaticDir, "index.html")))
 	}
-	httpServer := httpHandler.NewServer(cfg, greeterUseCase)
-
-	// Запускаем серверы
-	grpcAddr := "0.0.0.0:" + grpcPort
-	httpAddr := "0.0.0.0:" + httpPort
 
-	go func() {
-		lis, err := net.Listen("tcp", grpcAddr)
-		if err != nil {
-			logger.Error(ctx, "Failed to listen gRPC", "error", err)
-			os.Exit(1)
+	// 3. Hot reload: log.level и профилирование (Pyroscope, profile_types) меняются без рестарта пода
+	watchCtx, stopWatch := context.WithCancel(ctx)
+	defer stopWatch()
+	if err := loader.Watch(watchCtx, func(old, new config.AppConfig) {
+		if old.Log.Level != new.Log.Level {
+			logger.SetLevel(new.Log.Level)
 		}
-		logger.Info(ctx, "✅ Greeter gRPC listening", "addr", grpcAddr)
-		if err := grpcServer.Serve(lis); err != nil {
-			logger.Error(ctx, "Failed to serve gRPC", "error", err)
+		profilerCfg := telemetry.Config(new.Telemetry)
+		profilerCfg.ServiceName = serviceName
+		if err := telemetry.StartProfiler(profilerCfg); err != nil {
+			logger.Error(context.Background(), "Failed to reload profiler", "error", err)
 		}
-	}()
-
-	go func() {
-		logger.Info(ctx, "✅ Greeter HTTP listening", "addr", httpAddr)
-		httpServer.SetAddr(httpAddr)
-		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Error(ctx, "Failed to serve HTTP", "error", err)
-			os.Exit(1)
-		}
-	}()
+	}); err != nil {
+		logger.Warn(ctx, "⚠️ Config hot reload disabled", "error", err)
+	}
 
-	// Graceful shutdown
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	logger.Info(ctx, "Starting service",
+		"env", loader.Env(),
+		"http_port", cfg.Server.HTTPPort,
+		"grpc_port", cfg.Server.GRPCPort,
+	)
 
-	logger.Info(ctx, "Shutting down servers...")
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	// 4. Application Core
+	greeter := application.NewGreeterUseCase()
 
-	grpcServer.GracefulStop()
-	if err := httpServer.Shutdown(shutdownCtx); err != nil {
-		logger.Error(ctx, "HTTP shutdown error", "error", err)
-	}
-	logger.Info(ctx, "Servers stopped")
+	// 5. HTTP Server
+	admin := http.NewServeMux()
+	admin.Handle("/admin/config", loader.Handler())
+	admin.Handle("/admin/log/level", logger.LevelHandler())
+
+	httpSrv := http_handler.NewServer(&cfg, greeter, admin, probes)
+	runner.Add(app.HTTP("0.0.0.0:"+cfg.Server.HTTPPort, httpSrv))
+
+	// 6. gRPC Server
+	s := grpc.NewServer(
+		telemetry.GRPCServerOption(),
+		grpc.ChainUnaryInterceptor(logger.UnaryServerInterceptor(), telemetry.ProfileUnaryInterceptor(), apperrors.UnaryServerInterceptor()),
+	)
+
+	pb.RegisterGreeterServer(s, grpc_handler.NewHandler(greeter))
+	reflection.Register(s)
+	probes.RegisterGRPC(s)
+
+	runner.Add(app.GRPC(":"+cfg.Server.GRPCPort, s))
+	// Последним: при остановке первым снимает готовность, пока серверы еще обслуживают запросы
+	runner.Add(app.Component{Name: "health", Run: probes.Run, Stop: probes.Shutdown})
+
+	// 7. Запуск до SIGTERM или первой ошибки сервера; при ошибке - выход с кодом 1
+	runner.Main()
 }
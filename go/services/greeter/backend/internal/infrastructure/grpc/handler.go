@@ -4,24 +4,24 @@ import (
 	"context"
 
 	"greeter/internal/application"
+	"greeter/internal/domain"
+	apperrors "greeter/pkg/errors"
 	"greeter/pkg/logger"
-	pb "greeter/proto/helloworld"
-
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/reflection"
+	pb "greeter/pkg/proto/helloworld"
 )
 
+// server реализует интерфейс gRPC сервера, сгенерированный protoc
 type server struct {
 	pb.UnimplementedGreeterServer
 	useCase *application.GreeterUseCase
 }
 
-// NewServer теперь принимает опции gRPC для внедрения интерцепторов (Observability)
-func NewServer(useCase *application.GreeterUseCase, opts ...grpc.ServerOption) *grpc.Server {
-	s := grpc.NewServer(opts...)
-	pb.RegisterGreeterServer(s, &server{useCase: useCase})
-	reflection.Register(s)
-	return s
+// NewHandler создает экземпляр обработчика gRPC.
+// Сам *grpc.Server создается в main.go (Composition Root).
+func NewHandler(useCase *application.GreeterUseCase) pb.GreeterServer {
+	return &server{
+		useCase: useCase,
+	}
 }
 
 func (s *server) SayHello(ctx context.Context, in *pb.HelloRequest) (*pb.HelloReply, error) {
@@ -31,7 +31,8 @@ func (s *server) SayHello(ctx context.Context, in *pb.HelloRequest) (*pb.HelloRe
 	message, err := s.useCase.GreetUser(ctx, in.GetName())
 	if err != nil {
 		logger.Error(ctx, "UseCase error", "error", err)
-		return nil, err
+		// Коды и errdetails проставляет apperrors.UnaryServerInterceptor
+		return nil, apperrors.Classify(err, apperrors.On(apperrors.CodeInvalidArgument, domain.ErrEmptyName))
 	}
 
 	return &pb.HelloReply{Message: message}, nil
@@ -3,15 +3,20 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"net"
 	"net/http"
 	"path/filepath"
+	"time"
 
 	"greeter/internal/application"
+	"greeter/internal/domain"
 	"greeter/internal/middleware"
 	"greeter/pkg/config"
+	apperrors "greeter/pkg/errors"
+	"greeter/pkg/health"
 	"greeter/pkg/logger"
+	"greeter/pkg/telemetry"
 
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
@@ -21,7 +26,9 @@ type Server struct {
 	config  *config.AppConfig
 }
 
-func NewServer(cfg *config.AppConfig, useCase *application.GreeterUseCase) *Server {
+// admin обслуживает /admin/* (конфигурация, уровень логов), probes - /livez и /readyz; собираются в main.
+// /admin/* закрыт токеном server.admin_token (telemetry.RequireToken)
+func NewServer(cfg *config.AppConfig, useCase *application.GreeterUseCase, admin http.Handler, probes *health.Registry) *Server {
 	mux := http.NewServeMux()
 	s := &Server{
 		useCase: useCase,
@@ -29,13 +36,16 @@ func NewServer(cfg *config.AppConfig, useCase *application.GreeterUseCase) *Serv
 	}
 
 	// Observability
-	mux.Handle("/metrics", promhttp.Handler())
+	mux.Handle("/metrics", telemetry.MetricsHandler())
 
-	handleGreet := http.HandlerFunc(s.HandleGreet)
+	// Ошибки обработчика отдаются как application/problem+json
+	handleGreet := apperrors.HandlerFunc(s.HandleGreet)
 	mux.Handle("/api/hello", otelhttp.NewHandler(handleGreet, "HTTP /api/hello"))
 
-	handleHealth := http.HandlerFunc(s.HandleHealth)
-	mux.Handle("/health", otelhttp.NewHandler(handleHealth, "HTTP /health"))
+	mux.Handle("/livez", probes.LiveHandler())
+	mux.Handle("/readyz", probes.ReadyHandler())
+	mux.Handle("/health", probes.ReadyHandler()) // Прежний путь проверок
+	mux.Handle("/admin/", telemetry.RequireToken(cfg.Server.AdminToken, "admin", admin))
 
 	if cfg.Server.StaticDir != "" {
 		fs := http.FileServer(http.Dir(cfg.Server.StaticDir))
@@ -48,13 +58,14 @@ func NewServer(cfg *config.AppConfig, useCase *application.GreeterUseCase) *Serv
 		}))
 	}
 
-	handler := middleware.CORS(mux)
+	handler := middleware.CORS(telemetry.ProfileHTTPMiddleware(mux, logger.HTTPMiddleware(mux)))
 
 	// Слушаем на 0.0.0.0, чтобы было видно из Docker
 	addr := "0.0.0.0:" + cfg.Server.HTTPPort
 	s.server = &http.Server{
-		Addr:    addr,
-		Handler: handler,
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second, // G112: Protection against Slowloris
 	}
 
 	return s
@@ -65,7 +76,7 @@ func (s *Server) SetAddr(addr string) {
 	s.server.Addr = addr
 }
 
-func (s *Server) HandleGreet(w http.ResponseWriter, r *http.Request) {
+func (s *Server) HandleGreet(w http.ResponseWriter, r *http.Request) error {
 	ctx := r.Context()
 	// Логируем с контекстом, чтобы TraceID попал в логи (если логгер поддерживает)
 	logger.Info(ctx, "Handling Greet Request", "method", r.Method, "url", r.URL.String())
@@ -78,25 +89,19 @@ func (s *Server) HandleGreet(w http.ResponseWriter, r *http.Request) {
 	message, err := s.useCase.GreetUser(ctx, name)
 	if err != nil {
 		logger.Error(ctx, "Greeting failed", "error", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return apperrors.Classify(err, apperrors.On(apperrors.CodeInvalidArgument, domain.ErrEmptyName))
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{
 		"message": message,
 	})
+	return nil
 }
 
-func (s *Server) HandleHealth(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"status": "healthy",
-	})
-}
-
-func (s *Server) ListenAndServe() error {
-	return s.server.ListenAndServe()
+// Serve обслуживает запросы на lis; порт занимает app.HTTP
+func (s *Server) Serve(lis net.Listener) error {
+	return s.server.Serve(lis)
 }
 
 func (s *Server) Shutdown(ctx context.Context) error {
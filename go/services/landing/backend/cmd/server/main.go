@@ -2,30 +2,45 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"log"
-	"net"
 	"net/http"
 	"os"
-	"os/signal"
 	"path/filepath"
-	"syscall"
 
 	"landing/internal/application"
 	grpc_handler "landing/internal/infrastructure/grpc"
 	http_handler "landing/internal/infrastructure/http"
+	"landing/pkg/app"
 	"landing/pkg/config"
+	apperrors "landing/pkg/errors"
+	"landing/pkg/flags"
+	"landing/pkg/health"
 	"landing/pkg/logger"
 	pb "landing/pkg/proto/helloworld"
 	"landing/pkg/telemetry"
 
-	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
-	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/propagation"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 )
 
 func main() {
+	printConfig := flag.Bool("print-config", false, "print effective config with value sources and exit")
+	printSchema := flag.Bool("config-schema", false, "print JSON Schema of yaml/toml config files and exit")
+	printReference := flag.Bool("config-reference", false, "print markdown reference of config keys and exit")
+	flag.Parse()
+
+	// Схема и справочник не зависят от окружения: печатаем до загрузки конфигурации
+	if *printSchema {
+		_ = config.WriteSchema(os.Stdout)
+		return
+	}
+	if *printReference {
+		_ = config.WriteReference(os.Stdout)
+		return
+	}
+
 	// 1. Config (Prefix: LANDING)
 	loader := config.NewLoader("LANDING")
 	if err := loader.Load(); err != nil {
@@ -34,8 +49,16 @@ func main() {
 
 	var cfg config.AppConfig
 	if err := loader.Unmarshal(&cfg); err != nil {
+		if *printConfig {
+			// Показываем конфиг и при ошибке валидации: видно, откуда пришло невалидное значение
+			_ = loader.PrintConfig(os.Stdout, &cfg)
+		}
 		log.Fatalf("Failed to unmarshal config: %v", err)
 	}
+	if *printConfig {
+		_ = loader.PrintConfig(os.Stdout, &cfg)
+		return
+	}
 
 	serviceName := cfg.Telemetry.ServiceName
 	if serviceName == "" {
@@ -43,7 +66,7 @@ func main() {
 	}
 
 	// ИСПРАВЛЕНИЕ: Удален аргумент OTel Endpoint.
-	logger.Init(serviceName, cfg.Log.Level)
+	logger.Init(serviceName, logger.Config(cfg.Log))
 	logger.Info(context.Background(), "🚀 Logger initialized", "level", cfg.Log.Level)
 
 	// --- FIX: Resolve Static Directory ---
@@ -60,25 +83,41 @@ func main() {
 		cfg.Server.StaticDir = resolvedStaticDir
 	}
 
-	// 2. Telemetry
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
-
-	shutdownTracer, err := telemetry.InitTracer(
-		context.Background(),
-		serviceName,
-		cfg.Telemetry.OtelEndpoint,
-	)
+	// 2. Telemetry: трейсы, метрики, логи в OTLP, профилирование (секция telemetry)
+	telemetryCfg := telemetry.Config(cfg.Telemetry)
+	telemetryCfg.ServiceName = serviceName
+	shutdownTelemetry, err := telemetry.Setup(context.Background(), telemetryCfg)
 	if err != nil {
-		logger.Error(context.Background(), "Failed to init tracer", "error", err)
+		logger.Error(context.Background(), "⚠️ Telemetry partially initialized", "error", err)
 	}
-	defer func() { _ = shutdownTracer(context.Background()) }()
 
-	_ = telemetry.InitProfiler(serviceName, cfg.Telemetry.PyroscopeEndpoint)
+	// Жизненный цикл: компоненты останавливаются в обратном порядке, телеметрия - последней
+	runner := app.New(cfg.Server.ShutdownTimeout)
+	runner.Add(app.Component{Name: "telemetry", Stop: shutdownTelemetry})
 
-	// --- NEW: Metrics ---
-	metricsHandler, err := telemetry.InitMetrics(serviceName)
-	if err != nil {
-		logger.Error(context.Background(), "Failed to init metrics", "error", err)
+	// 3. Проверки состояния: /livez, /readyz и grpc.health.v1 (пробы k8s, health check Envoy)
+	probes := health.New(cfg.Server.HealthTimeout, cfg.Server.HealthCacheTTL, cfg.Server.ShutdownDrain)
+	if endpoint := telemetryCfg.CollectorEndpoint(); endpoint != "" {
+		probes.Add("otel-collector", health.Dial(endpoint), health.Optional())
+	}
+	if cfg.Server.StaticDir != "" {
+		probes.Add("static", health.File(filepath.Join(cfg.Server.StaticDir, "index.html")))
+	}
+
+	// Hot reload: log.level и профилирование (Pyroscope, profile_types) меняются без рестарта пода
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	if err := loader.Watch(watchCtx, func(old, new config.AppConfig) {
+		if old.Log.Level != new.Log.Level {
+			logger.SetLevel(new.Log.Level)
+		}
+		profilerCfg := telemetry.Config(new.Telemetry)
+		profilerCfg.ServiceName = serviceName
+		if err := telemetry.StartProfiler(profilerCfg); err != nil {
+			logger.Error(context.Background(), "Failed to reload profiler", "error", err)
+		}
+	}); err != nil {
+		logger.Warn(context.Background(), "⚠️ Config hot reload disabled", "error", err)
 	}
 
 	logger.Info(context.Background(), "Starting service",
@@ -87,59 +126,45 @@ func main() {
 		"static_dir", cfg.Server.StaticDir,
 	)
 
-	// 4. Application Core
-	greeter := application.NewGreeterUseCase()
-
-	// 5. HTTP Server
-	http.Handle("/metrics", metricsHandler)
+	// 4. Фич-флаги (configs/flags.yaml), перечитываются без рестарта
+	featureFlags, err := flags.New(filepath.Join(loader.Dir(), "flags.yaml"), loader.Env())
+	if err != nil {
+		runner.Fail(fmt.Errorf("failed to load feature flags: %w", err))
+	}
+	if err := featureFlags.Watch(watchCtx); err != nil {
+		logger.Warn(context.Background(), "⚠️ Feature flags hot reload disabled", "error", err)
+	}
 
-	httpSrv := http_handler.NewServer(&cfg, greeter)
+	// 5. Application Core
+	greeter := application.NewGreeterUseCase(featureFlags)
 
-	errChan := make(chan error, 1)
+	// 6. HTTP Server
+	admin := http.NewServeMux()
+	admin.Handle("/admin/config", loader.Handler())
+	admin.Handle("/admin/log/level", logger.LevelHandler())
+	admin.Handle("/admin/flags", featureFlags.Handler())
 
-	go func() {
-		if err := httpSrv.ListenAndServe(); err != nil {
-			errChan <- err
-		}
-	}()
-
-	// 6. gRPC Server
-	lis, err := net.Listen("tcp", ":"+cfg.Server.GRPCPort)
-	if err != nil {
-		logger.Error(context.Background(), "Failed to listen TCP", "error", err)
-		return
-	}
+	httpSrv := http_handler.NewServer(&cfg, greeter, admin, probes)
+	runner.Add(app.HTTP("0.0.0.0:"+cfg.Server.HTTPPort, httpSrv))
 
+	// 7. gRPC Server
 	s := grpc.NewServer(
-		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		telemetry.GRPCServerOption(),
+		grpc.ChainUnaryInterceptor(logger.UnaryServerInterceptor(), telemetry.ProfileUnaryInterceptor(), apperrors.UnaryServerInterceptor()),
 	)
 
 	grpcServerHandler := grpc_handler.NewHandler(greeter)
 
 	pb.RegisterGreeterServer(s, grpcServerHandler)
 	reflection.Register(s)
+	probes.RegisterGRPC(s)
 
-	go func() {
-		if err := s.Serve(lis); err != nil {
-			errChan <- err
-		}
-	}()
-
-	// 7. Shutdown
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	runner.Add(app.GRPC(":"+cfg.Server.GRPCPort, s))
+	// Последним: при остановке первым снимает готовность, пока серверы еще обслуживают запросы
+	runner.Add(app.Component{Name: "health", Run: probes.Run, Stop: probes.Shutdown})
 
-	select {
-	case <-quit:
-		logger.Info(context.Background(), "Shutting down servers...")
-	case err := <-errChan:
-		logger.Error(context.Background(), "Server startup failed", "error", err)
-	}
-
-	s.GracefulStop()
-	if err := httpSrv.Shutdown(context.Background()); err != nil {
-		logger.Error(context.Background(), "HTTP server shutdown error", "error", err)
-	}
+	// 8. Запуск до SIGTERM или первой ошибки сервера; при ошибке - выход с кодом 1
+	runner.Main()
 }
 
 func resolveStaticDir(configPath string) string {
@@ -5,13 +5,22 @@ import (
 	"landing/internal/domain"
 )
 
+// GreetingStyleFlag - эксперимент со стилем приветствия (варианты: classic, friendly)
+const GreetingStyleFlag = "landing.greeting-style"
+
+// FeatureFlags - порт для фич-флагов (реализация: pkg/flags)
+type FeatureFlags interface {
+	Variant(ctx context.Context, key, userID string) string
+}
+
 // GreeterUseCase - application service
 type GreeterUseCase struct {
 	// В будущем добавишь repository, external services, etc.
+	flags FeatureFlags
 }
 
-func NewGreeterUseCase() *GreeterUseCase {
-	return &GreeterUseCase{}
+func NewGreeterUseCase(flags FeatureFlags) *GreeterUseCase {
+	return &GreeterUseCase{flags: flags}
 }
 
 func (uc *GreeterUseCase) GreetUser(ctx context.Context, name string) (string, error) {
@@ -22,5 +31,9 @@ func (uc *GreeterUseCase) GreetUser(ctx context.Context, name string) (string, e
 
 	// Тут может быть логика сохранения в БД, отправка события и т.д.
 
+	// Пользователя пока нет, эксперимент делится по имени
+	if uc.flags.Variant(ctx, GreetingStyleFlag, landing.Name()) == "friendly" {
+		return landing.GenerateFriendlyGreeting(), nil
+	}
 	return landing.GenerateGreeting(), nil
 }
@@ -18,6 +18,10 @@ func (g *Greeter) GenerateGreeting() string {
 	return fmt.Sprintf("Hello %s from Greeter Domain!", g.name)
 }
 
+func (g *Greeter) GenerateFriendlyGreeting() string {
+	return fmt.Sprintf("Hi %s, glad to see you on Landing!", g.name)
+}
+
 func (g *Greeter) Name() string {
 	return g.name
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"landing/internal/application"
+	"landing/internal/domain"
+	apperrors "landing/pkg/errors"
 	"landing/pkg/logger"
 	pb "landing/pkg/proto/helloworld"
 
@@ -31,7 +33,8 @@ func (s *server) SayHello(ctx context.Context, in *pb.HelloRequest) (*pb.HelloRe
 	message, err := s.useCase.GreetUser(ctx, in.GetName())
 	if err != nil {
 		logger.Error(ctx, "UseCase error", "error", err)
-		return nil, err
+		// Коды и errdetails проставляет apperrors.UnaryServerInterceptor
+		return nil, apperrors.Classify(err, apperrors.On(apperrors.CodeInvalidArgument, domain.ErrEmptyName))
 	}
 
 	return &pb.HelloReply{Message: message}, nil
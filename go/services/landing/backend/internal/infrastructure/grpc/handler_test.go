@@ -0,0 +1,54 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"landing/internal/application"
+	apperrors "landing/pkg/errors"
+	"landing/pkg/logger"
+	pb "landing/pkg/proto/helloworld"
+
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type staticFlags string
+
+func (f staticFlags) Variant(context.Context, string, string) string { return string(f) }
+
+func TestSayHelloErrors(t *testing.T) {
+	// SayHello пишет в глобальный логгер
+	logger.Init("landing-test", logger.Config{Level: "error"})
+
+	srv := NewHandler(application.NewGreeterUseCase(staticFlags("classic")))
+	interceptor := apperrors.UnaryServerInterceptor()
+
+	tests := []struct {
+		name string
+		in   string
+		code codes.Code
+		msg  string
+	}{
+		{"empty name", "", codes.InvalidArgument, "name cannot be empty"},
+		{"valid name", "Alice", codes.OK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := interceptor(context.Background(), &pb.HelloRequest{Name: tt.in}, &grpclib.UnaryServerInfo{},
+				func(ctx context.Context, req any) (any, error) {
+					return srv.SayHello(ctx, req.(*pb.HelloRequest))
+				})
+
+			st := status.Convert(err)
+			if st.Code() != tt.code || st.Message() != tt.msg {
+				t.Errorf("status = %v %q, want %v %q", st.Code(), st.Message(), tt.code, tt.msg)
+			}
+			if tt.code == codes.OK && resp.(*pb.HelloReply).GetMessage() != "Hello Alice from Greeter Domain!" {
+				t.Errorf("reply = %v", resp)
+			}
+		})
+	}
+}
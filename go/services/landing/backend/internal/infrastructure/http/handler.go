@@ -3,17 +3,21 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
 	"time"
 
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"landing/internal/application"
+	"landing/internal/domain"
 	"landing/internal/middleware"
 	"landing/pkg/config"
+	apperrors "landing/pkg/errors"
+	"landing/pkg/health"
 	"landing/pkg/logger"
+	"landing/pkg/telemetry"
 )
 
 type Server struct {
@@ -22,21 +26,26 @@ type Server struct {
 	config  *config.AppConfig
 }
 
-func NewServer(cfg *config.AppConfig, useCase *application.GreeterUseCase) *Server {
+// admin обслуживает /admin/* (конфигурация, фич-флаги, уровень логов), probes - /livez и /readyz; собираются в main.
+// /admin/* закрыт токеном server.admin_token (telemetry.RequireToken)
+func NewServer(cfg *config.AppConfig, useCase *application.GreeterUseCase, admin http.Handler, probes *health.Registry) *Server {
 	mux := http.NewServeMux()
 	s := &Server{
 		useCase: useCase,
 		config:  cfg,
 	}
 
-	mux.Handle("/metrics", promhttp.Handler())
+	mux.Handle("/metrics", telemetry.MetricsHandler())
 
-	handleGreet := http.HandlerFunc(s.HandleGreet)
+	// Ошибки обработчика отдаются как application/problem+json
+	handleGreet := apperrors.HandlerFunc(s.HandleGreet)
 	// Используем otelhttp для замеров задержек HTTP уровня
 	mux.Handle("/hello", otelhttp.NewHandler(handleGreet, "HTTP /hello"))
 
-	handleHealth := http.HandlerFunc(s.HandleHealth)
-	mux.Handle("/health", handleHealth)
+	mux.Handle("/livez", probes.LiveHandler())
+	mux.Handle("/readyz", probes.ReadyHandler())
+	mux.Handle("/health", probes.ReadyHandler()) // Прежний путь проверок
+	mux.Handle("/admin/", telemetry.RequireToken(cfg.Server.AdminToken, "admin", admin))
 
 	// Статика
 	if cfg.Server.StaticDir != "" {
@@ -63,7 +72,7 @@ func NewServer(cfg *config.AppConfig, useCase *application.GreeterUseCase) *Serv
 		}))
 	}
 
-	handler := middleware.CORS(mux)
+	handler := middleware.CORS(telemetry.ProfileHTTPMiddleware(mux, logger.HTTPMiddleware(mux)))
 
 	s.server = &http.Server{
 		Addr:              "0.0.0.0:" + cfg.Server.HTTPPort,
@@ -78,7 +87,7 @@ func (s *Server) SetAddr(addr string) {
 	s.server.Addr = addr
 }
 
-func (s *Server) HandleGreet(w http.ResponseWriter, r *http.Request) {
+func (s *Server) HandleGreet(w http.ResponseWriter, r *http.Request) error {
 	// otelhttp уже извлек контекст из заголовков Envoy
 	ctx := r.Context()
 
@@ -88,27 +97,19 @@ func (s *Server) HandleGreet(w http.ResponseWriter, r *http.Request) {
 	}
 	message, err := s.useCase.GreetUser(ctx, name)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return apperrors.Classify(err, apperrors.On(apperrors.CodeInvalidArgument, domain.ErrEmptyName))
 	}
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(map[string]string{"message": message, "source": "Landing Service"}); err != nil {
 		// Используем структурный логгер вместо log.Printf
 		logger.Error(ctx, "Failed to encode response", "error", err)
 	}
+	return nil
 }
 
-func (s *Server) HandleHealth(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(map[string]string{"status": "healthy"}); err != nil {
-		// Используем структурный логгер
-		// В HealthCheck контекст часто короткоживущий, но все же передаем
-		logger.Error(r.Context(), "Failed to encode health response", "error", err)
-	}
-}
-
-func (s *Server) ListenAndServe() error {
-	return s.server.ListenAndServe()
+// Serve обслуживает запросы на lis; порт занимает app.HTTP
+func (s *Server) Serve(lis net.Listener) error {
+	return s.server.Serve(lis)
 }
 
 func (s *Server) Shutdown(ctx context.Context) error {
@@ -3,27 +3,29 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"net"
 	"net/http"
 	"os"
-	"os/signal"
+	"reflect"
 	"sync"
-	"syscall"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/segmentio/kafka-go"
-	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 
+	"notification/pkg/app"
 	"notification/pkg/config"
+	apperrors "notification/pkg/errors"
+	"notification/pkg/health"
 	"notification/pkg/logger"
+	"notification/pkg/metrics"
 	notification_pb "notification/pkg/proto/notification"
 	"notification/pkg/telemetry"
 )
@@ -39,12 +41,28 @@ type NotificationServer struct {
 
 	clients map[*websocket.Conn]bool
 	mu      sync.RWMutex
+	metrics *metrics.Notification
 }
 
 func NewNotificationServer() *NotificationServer {
-	return &NotificationServer{
+	s := &NotificationServer{
 		clients: make(map[*websocket.Conn]bool),
 	}
+
+	m, err := metrics.NewNotification(s.clientCount)
+	if err != nil {
+		// Без метрик рассылка работает как раньше
+		logger.Warn(context.Background(), "⚠️ Business metrics disabled", "error", err)
+	}
+	s.metrics = m
+	return s
+}
+
+// clientCount - число открытых WebSocket-соединений для notification_ws_connections
+func (s *NotificationServer) clientCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.clients)
 }
 
 func (s *NotificationServer) AddClient(conn *websocket.Conn) {
@@ -66,7 +84,9 @@ func (s *NotificationServer) RemoveClient(conn *websocket.Conn) {
 	}
 }
 
-func (s *NotificationServer) Broadcast(ctx context.Context, payload []byte) {
+// Broadcast пишет payload всем WebSocket-клиентам. createdAt - время создания сообщения в chat
+// (domain.NewMessage) для chat_delivery_latency_seconds; нулевое - задержка не учитывается.
+func (s *NotificationServer) Broadcast(ctx context.Context, payload []byte, createdAt time.Time) {
 	s.mu.RLock()
 	conns := make([]*websocket.Conn, 0, len(s.clients))
 	for client := range s.clients {
@@ -77,21 +97,30 @@ func (s *NotificationServer) Broadcast(ctx context.Context, payload []byte) {
 	// Логируем попытку бродкаста, чтобы видеть, доходит ли вообще дело до сюда
 	logger.Info(ctx, "📢 Broadcasting message to clients", "count", len(conns))
 
+	start := time.Now()
+	failures := 0
 	messageType := websocket.TextMessage
 	for _, conn := range conns {
 		err := conn.WriteMessage(messageType, payload)
 		if err != nil {
+			failures++
 			logger.Error(ctx, "Error broadcasting to client", "error", err)
 			s.RemoveClient(conn)
 		}
 	}
+
+	s.metrics.Broadcast(ctx, time.Since(start), failures)
+	if failures < len(conns) {
+		// Сообщение дошло хотя бы до одного клиента
+		s.metrics.Delivered(ctx, createdAt)
+	}
 }
 
 func (s *NotificationServer) Send(
 	ctx context.Context,
 	req *notification_pb.SendRequest,
 ) (*notification_pb.SendReply, error) {
-	s.Broadcast(ctx, req.PayloadJson)
+	s.Broadcast(ctx, req.PayloadJson, time.Time{})
 	return &notification_pb.SendReply{Success: true}, nil
 }
 
@@ -142,23 +171,54 @@ func (c *kafkaHeaderCarrier) Keys() []string {
 	return keys
 }
 
+// defaultGroupID - группа консьюмеров, если kafka.group_id не задан
+const defaultGroupID = "notification-group"
+
+// consumerGroup возвращает kafka.group_id или группу по умолчанию
+func consumerGroup(cfg config.KafkaConfig) string {
+	if cfg.GroupID == "" {
+		return defaultGroupID
+	}
+	return cfg.GroupID
+}
+
 type KafkaConsumer struct {
-	reader *kafka.Reader
-	hub    *NotificationServer
-	tracer trace.Tracer
-	topic  string
+	// mu защищает reader и cfg: Reconfigure подменяет reader на лету
+	mu      sync.RWMutex
+	reader  *kafka.Reader
+	cfg     config.KafkaConfig
+	hub     *NotificationServer
+	tracer  trace.Tracer
+	metrics *consumerMetrics
 }
 
-func NewKafkaConsumer(brokers []string, topic, groupID string, hub *NotificationServer) *KafkaConsumer {
+func NewKafkaConsumer(cfg config.KafkaConfig, hub *NotificationServer) *KafkaConsumer {
+	c := &KafkaConsumer{
+		reader: newReader(cfg),
+		cfg:    cfg,
+		hub:    hub,
+		tracer: otel.Tracer("kafka-consumer"),
+	}
+
+	metrics, err := newConsumerMetrics(c.stats)
+	if err != nil {
+		// Без метрик консьюмер работает как раньше
+		logger.Warn(context.Background(), "⚠️ [Kafka] Consumer metrics disabled", "error", err)
+	}
+	c.metrics = metrics
+	return c
+}
+
+func newReader(cfg config.KafkaConfig) *kafka.Reader {
 	// Включаем подробное логирование ВНУТРИ драйвера Kafka
 	// Это покажет, почему он молчит (Connect Timeout, DNS error, Rebalancing...)
-	r := kafka.NewReader(kafka.ReaderConfig{
-		Brokers:        brokers,
-		Topic:          topic,
-		GroupID:        groupID,
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:        cfg.Brokers,
+		Topic:          cfg.Topic,
+		GroupID:        consumerGroup(cfg),
 		MinBytes:       1,
 		MaxBytes:       10e6,
-		MaxWait:        500 * time.Millisecond,
+		MaxWait:        cfg.MaxWait,
 		CommitInterval: 1 * time.Second,
 		StartOffset:    kafka.FirstOffset,
 		// ВАЖНО: Логгеры для диагностики
@@ -171,31 +231,61 @@ func NewKafkaConsumer(brokers []string, topic, groupID string, hub *Notification
 			logger.Error(context.Background(), fmt.Sprintf("KAFKA DRIVER ERROR: "+msg, args...))
 		}),
 	})
+}
 
-	return &KafkaConsumer{
-		reader: r,
-		hub:    hub,
-		topic:  topic,
-		tracer: otel.Tracer("kafka-consumer"),
+// Reconfigure пересоздает reader с новыми брокерами/топиком/группой/таймаутами (hot reload конфига).
+// Закрытие старого reader'а прерывает текущий ReadMessage, и цикл Start продолжает с новым.
+func (c *KafkaConsumer) Reconfigure(cfg config.KafkaConfig) {
+	c.mu.Lock()
+	if reflect.DeepEqual(c.cfg, cfg) {
+		c.mu.Unlock()
+		return
+	}
+	old := c.reader
+	c.reader = newReader(cfg)
+	c.cfg = cfg
+	c.mu.Unlock()
+
+	logger.Info(context.Background(), "🔄 [Kafka] Consumer reconfigured", "brokers", cfg.Brokers, "topic", cfg.Topic, "group_id", consumerGroup(cfg), "max_wait", cfg.MaxWait)
+
+	if err := old.Close(); err != nil {
+		logger.Error(context.Background(), "❌ [Kafka] Failed to close previous reader", "error", err)
 	}
 }
 
+// current возвращает текущий reader, его топик и группу
+func (c *KafkaConsumer) current() (*kafka.Reader, string, string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.reader, c.cfg.Topic, consumerGroup(c.cfg)
+}
+
 func (c *KafkaConsumer) Start(ctx context.Context) {
-	logger.Info(ctx, "📥 [Kafka] Consumer loop starting...", "topic", c.topic)
+	_, startTopic, startGroup := c.current()
+	logger.Info(ctx, "📥 [Kafka] Consumer loop starting...", "topic", startTopic, "group_id", startGroup)
 	for {
+		reader, topic, groupID := c.current()
+
 		// Блокирующий вызов. Если драйвер не может соединиться, он будет висеть здесь
 		// и кидать ошибки в ErrorLogger (который мы добавили выше).
-		m, err := c.reader.ReadMessage(ctx)
+		m, err := reader.ReadMessage(ctx)
 		if err != nil {
 			if ctx.Err() != nil {
 				logger.Info(ctx, "📥 [Kafka] Context cancelled, stopping consumer")
 				return
 			}
+			if next, _, _ := c.current(); next != reader {
+				// Reader заменен через Reconfigure - продолжаем с новым
+				continue
+			}
+			c.metrics.failed(ctx, topic, groupID, "read")
 			logger.Error(ctx, "❌ [Kafka] ReadMessage returned error", "error", err)
 			time.Sleep(1 * time.Second)
 			continue
 		}
 
+		c.metrics.received(ctx, topic, groupID, len(m.Value))
+
 		// 1. Trace Propagation
 		carrier := &kafkaHeaderCarrier{msg: &m}
 		propagator := otel.GetTextMapPropagator()
@@ -206,21 +296,29 @@ func (c *KafkaConsumer) Start(ctx context.Context) {
 			trace.WithSpanKind(trace.SpanKindConsumer),
 			trace.WithAttributes(
 				semconv.MessagingSystem("kafka"),
-				semconv.MessagingDestinationName(c.topic),
+				semconv.MessagingDestinationName(topic),
 				semconv.MessagingKafkaMessageKey(eventName),
 				semconv.MessagingOperationProcess,
 				attribute.Int64("kafka.offset", m.Offset),
 			),
 		)
 
+		// Все логи обработки сообщения несут тип события и позицию в топике
+		spanCtx = logger.With(spanCtx,
+			logger.KeyEventType, eventName,
+			logger.KeyKafkaOffset, m.Offset,
+			"kafka.partition", m.Partition,
+		)
+
 		// Логируем факт получения пакета (даже если не сможем распарсить)
-		logger.Info(spanCtx, "📥 [Kafka] Packet received", "key", eventName, "offset", m.Offset)
+		logger.Info(spanCtx, "📥 [Kafka] Packet received")
 
 		// 2. Logic
 		if eventName == "chat.message_posted" {
 			var event MessagePostedEvent
 			if err := json.Unmarshal(m.Value, &event); err != nil {
-				logger.Error(spanCtx, "Failed to unmarshal event", "error", err, "raw", string(m.Value))
+				c.metrics.failed(spanCtx, topic, groupID, "decode")
+				logger.Error(spanCtx, "Failed to unmarshal event", "error", err, "raw", logger.Sensitive(string(m.Value)), "size", len(m.Value))
 				span.RecordError(err)
 			} else {
 				author := event.AuthorID
@@ -248,35 +346,63 @@ func (c *KafkaConsumer) Start(ctx context.Context) {
 				}
 
 				data, _ := json.Marshal(wsPayload)
-				c.hub.Broadcast(spanCtx, data)
+				c.hub.Broadcast(spanCtx, data, ts)
 			}
 		} else {
-			logger.Info(spanCtx, "⚠️ Ignored event key", "key", eventName)
+			logger.Info(spanCtx, "⚠️ Ignored event key")
 		}
 
 		span.End()
 	}
 }
 
+// stats - статистика текущего reader'а для consumerMetrics
+func (c *KafkaConsumer) stats() (kafka.ReaderStats, string, string) {
+	reader, topic, groupID := c.current()
+	return reader.Stats(), topic, groupID
+}
+
 func (c *KafkaConsumer) Close() error {
-	return c.reader.Close()
+	reader, _, _ := c.current()
+	return errors.Join(c.metrics.close(), reader.Close())
 }
 
 // --- Main ---
 
 func main() {
+	printConfig := flag.Bool("print-config", false, "print effective config with value sources and exit")
+	printSchema := flag.Bool("config-schema", false, "print JSON Schema of yaml/toml config files and exit")
+	printReference := flag.Bool("config-reference", false, "print markdown reference of config keys and exit")
+	flag.Parse()
+
+	// Схема и справочник не зависят от окружения: печатаем до загрузки конфигурации
+	if *printSchema {
+		_ = config.WriteSchema(os.Stdout)
+		return
+	}
+	if *printReference {
+		_ = config.WriteReference(os.Stdout)
+		return
+	}
+
 	loader := config.NewLoader("NOTIFICATION")
 	if err := loader.Load(); err != nil {
-		logger.Init("notification-bootstrap", "info")
-		logger.Error(context.Background(), "Failed to load config", "error", err)
-		os.Exit(1)
+		logger.Init("notification-bootstrap", logger.Config{Level: "info"})
+		app.New(0).Fail(fmt.Errorf("failed to load config: %w", err))
 	}
 
 	var cfg config.AppConfig
 	if err := loader.Unmarshal(&cfg); err != nil {
-		logger.Init("notification-bootstrap", "info")
-		logger.Error(context.Background(), "Failed to unmarshal config", "error", err)
-		os.Exit(1)
+		if *printConfig {
+			// Показываем конфиг и при ошибке валидации: видно, откуда пришло невалидное значение
+			_ = loader.PrintConfig(os.Stdout, &cfg)
+		}
+		logger.Init("notification-bootstrap", logger.Config{Level: "info"})
+		app.New(0).Fail(fmt.Errorf("failed to unmarshal config: %w", err))
+	}
+	if *printConfig {
+		_ = loader.PrintConfig(os.Stdout, &cfg)
+		return
 	}
 
 	serviceName := cfg.Telemetry.ServiceName
@@ -284,54 +410,75 @@ func main() {
 		serviceName = "notification-service"
 	}
 
-	logger.Init(serviceName, "info")
-
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
-		propagation.TraceContext{},
-		propagation.Baggage{},
-	))
+	logger.Init(serviceName, logger.Config(cfg.Log))
 
-	shutdownTracer, err := telemetry.InitTracer(
-		context.Background(),
-		serviceName,
-		cfg.Telemetry.OtelEndpoint,
-	)
+	// Telemetry: трейсы, метрики, логи в OTLP, профилирование (секция telemetry)
+	telemetryCfg := telemetry.Config(cfg.Telemetry)
+	telemetryCfg.ServiceName = serviceName
+	shutdownTelemetry, err := telemetry.Setup(context.Background(), telemetryCfg)
 	if err != nil {
-		logger.Error(context.Background(), "Failed to init tracer", "error", err)
-		shutdownTracer = func(context.Context) error { return nil }
+		logger.Error(context.Background(), "⚠️ Telemetry partially initialized", "error", err)
 	}
-	defer func() { _ = shutdownTracer(context.Background()) }()
 
-	_ = telemetry.InitProfiler(serviceName, cfg.Telemetry.PyroscopeEndpoint)
-
-	metricsHandler, err := telemetry.InitMetrics(serviceName)
-	if err != nil {
-		logger.Error(context.Background(), "Failed to init metrics", "error", err)
-	}
+	// Жизненный цикл: компоненты останавливаются в обратном порядке, телеметрия - последней
+	runner := app.New(cfg.Server.ShutdownTimeout)
+	runner.Add(app.Component{Name: "telemetry", Stop: shutdownTelemetry})
 
 	// Инициализируем Hub
 	srv := NewNotificationServer()
+	runner.Add(app.Component{
+		Name: "hub-metrics",
+		Stop: func(context.Context) error { return srv.metrics.Close() },
+	})
 
 	// Kafka Setup
 	brokers := cfg.Kafka.Brokers
 	// ЛОГИРУЕМ КОНФИГ ПРИ СТАРТЕ - Проверь эти логи!
-	logger.Info(context.Background(), "🔌 Kafka Config", "brokers", brokers, "topic", cfg.Kafka.Topic)
+	logger.Info(context.Background(), "🔌 Kafka Config", "brokers", brokers, "topic", cfg.Kafka.Topic, "group_id", consumerGroup(cfg.Kafka))
 
+	var kafkaConsumer *KafkaConsumer
 	if len(brokers) == 0 {
 		logger.Error(context.Background(), "❌ Kafka Brokers list is EMPTY! check configs/staging.env or local.env")
 	} else {
-		kafkaConsumer := NewKafkaConsumer(
-			brokers,
-			cfg.Kafka.Topic,
-			"notification-group",
-			srv,
-		)
-		defer kafkaConsumer.Close()
+		kafkaConsumer = NewKafkaConsumer(cfg.Kafka, srv)
+
+		// Цикл консьюмера прерывается отменой ctx при остановке, затем reader закрывается
+		runner.Add(app.Component{
+			Name: "kafka-consumer",
+			Run: func(ctx context.Context) error {
+				kafkaConsumer.Start(ctx)
+				return nil
+			},
+			Stop: func(context.Context) error { return kafkaConsumer.Close() },
+		})
+	}
+
+	// Проверки состояния: /livez, /readyz и grpc.health.v1 (пробы k8s, health check Envoy)
+	probes := health.New(cfg.Server.HealthTimeout, cfg.Server.HealthCacheTTL, cfg.Server.ShutdownDrain)
+	if kafkaConsumer != nil {
+		probes.Add("kafka", health.Dial(brokers...))
+	}
+	if endpoint := telemetryCfg.CollectorEndpoint(); endpoint != "" {
+		probes.Add("otel-collector", health.Dial(endpoint), health.Optional())
+	}
 
-		// Запускаем консьюмер
-		go func() {
-			kafkaConsumer.Start(context.Background())
-		}()
+	// Hot reload: log.level, профилирование и параметры Kafka меняются без рестарта пода
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	if err := loader.Watch(watchCtx, func(old, new config.AppConfig) {
+		if old.Log.Level != new.Log.Level {
+			logger.SetLevel(new.Log.Level)
+		}
+		profilerCfg := telemetry.Config(new.Telemetry)
+		profilerCfg.ServiceName = serviceName
+		if err := telemetry.StartProfiler(profilerCfg); err != nil {
+			logger.Error(context.Background(), "Failed to reload profiler", "error", err)
+		}
+		if kafkaConsumer != nil && len(new.Kafka.Brokers) > 0 {
+			kafkaConsumer.Reconfigure(new.Kafka)
+		}
+	}); err != nil {
+		logger.Warn(context.Background(), "⚠️ Config hot reload disabled", "error", err)
 	}
 
 	// HTTP Setup
@@ -355,59 +502,37 @@ func main() {
 		}
 	})
 
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(`{"status":"healthy","service":"notification"}`))
-	})
+	mux.Handle("/livez", probes.LiveHandler())
+	mux.Handle("/readyz", probes.ReadyHandler())
+	mux.Handle("/health", probes.ReadyHandler()) // Прежний путь проверок
 
-	if metricsHandler != nil {
-		mux.Handle("/metrics", metricsHandler)
-	}
+	mux.Handle("/metrics", telemetry.MetricsHandler())
+
+	// Итоговая конфигурация с источниками значений (секреты замаскированы) и уровень логов,
+	// только с Bearer server.admin_token
+	admin := http.NewServeMux()
+	admin.Handle("/admin/config", loader.Handler())
+	admin.Handle("/admin/log/level", logger.LevelHandler())
+	mux.Handle("/admin/", telemetry.RequireToken(cfg.Server.AdminToken, "admin", admin))
 
 	httpServer := &http.Server{
 		Addr:              ":" + cfg.Server.HTTPPort,
-		Handler:           mux,
+		Handler:           telemetry.ProfileHTTPMiddleware(mux, logger.HTTPMiddleware(mux)),
 		ReadHeaderTimeout: 5 * time.Second,
 	}
+	runner.Add(app.HTTP(httpServer.Addr, httpServer))
 
 	// gRPC Setup
-	lis, err := net.Listen("tcp", ":"+cfg.Server.GRPCPort)
-	if err != nil {
-		logger.Error(context.Background(), "Failed to listen", "error", err)
-		return
-	}
-
 	grpcServer := grpc.NewServer(
-		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		telemetry.GRPCServerOption(),
+		grpc.ChainUnaryInterceptor(logger.UnaryServerInterceptor(), telemetry.ProfileUnaryInterceptor(), apperrors.UnaryServerInterceptor()),
 	)
 	notification_pb.RegisterNotificationServiceServer(grpcServer, srv)
+	probes.RegisterGRPC(grpcServer)
+	runner.Add(app.GRPC(":"+cfg.Server.GRPCPort, grpcServer))
+	// Последним: при остановке первым снимает готовность, пока серверы еще обслуживают запросы
+	runner.Add(app.Component{Name: "health", Run: probes.Run, Stop: probes.Shutdown})
 
-	errChan := make(chan error, 1)
-	go func() {
-		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			errChan <- err
-		}
-	}()
-	go func() {
-		logger.Info(context.Background(), "gRPC server listening", "addr", lis.Addr())
-		if err := grpcServer.Serve(lis); err != nil {
-			errChan <- err
-		}
-	}()
-
-	logger.Info(context.Background(), "✅ Notification Service Started")
-
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-
-	select {
-	case <-quit:
-		logger.Info(context.Background(), "Shutting down...")
-	case err := <-errChan:
-		logger.Error(context.Background(), "Server failed", "error", err)
-	}
-
-	grpcServer.GracefulStop()
-	httpServer.Shutdown(context.Background())
+	// Запуск до SIGTERM или первой ошибки сервера; при ошибке - выход с кодом 1
+	runner.Main()
 }
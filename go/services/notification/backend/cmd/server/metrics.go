@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+// consumerMetrics - метрики консьюмера: сообщения, байты и ошибки считаются в цикле Start,
+// отставание и очередь снимаются из kafka.Reader.Stats() при каждом сборе (scrape /metrics).
+type consumerMetrics struct {
+	messages metric.Int64Counter
+	bytes    metric.Int64Counter
+	errors   metric.Int64Counter
+
+	registration metric.Registration
+}
+
+// newConsumerMetrics регистрирует метрики kafka.consumer.*; stats возвращает статистику текущего reader,
+// его топик и группу (Reconfigure их подменяет).
+func newConsumerMetrics(stats func() (kafka.ReaderStats, string, string)) (*consumerMetrics, error) {
+	meter := otel.Meter("kafka-consumer")
+
+	m := &consumerMetrics{}
+	var err error
+	if m.messages, err = meter.Int64Counter("kafka.consumer.messages",
+		metric.WithDescription("Messages read from Kafka"),
+		metric.WithUnit("{message}"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create kafka metric: %w", err)
+	}
+	if m.bytes, err = meter.Int64Counter("kafka.consumer.message.size",
+		metric.WithDescription("Payload bytes read from Kafka"),
+		metric.WithUnit("By"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create kafka metric: %w", err)
+	}
+	if m.errors, err = meter.Int64Counter("kafka.consumer.errors",
+		metric.WithDescription("Failed reads and undecodable messages (error.type=read|decode)"),
+		metric.WithUnit("{error}"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create kafka metric: %w", err)
+	}
+
+	lag, err := meter.Int64ObservableGauge("kafka.consumer.lag",
+		metric.WithDescription("Messages between the last read offset and the partition high watermark"),
+		metric.WithUnit("{message}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kafka metric: %w", err)
+	}
+	queue, err := meter.Int64ObservableGauge("kafka.consumer.queue.length",
+		metric.WithDescription("Fetched messages waiting for ReadMessage"),
+		metric.WithUnit("{message}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kafka metric: %w", err)
+	}
+
+	m.registration, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		s, topic, groupID := stats()
+		attrs := metric.WithAttributes(consumerAttrs(topic, groupID)...)
+		o.ObserveInt64(lag, s.Lag, attrs)
+		o.ObserveInt64(queue, s.QueueLength, attrs)
+		return nil
+	}, lag, queue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register kafka metrics: %w", err)
+	}
+	return m, nil
+}
+
+func (m *consumerMetrics) received(ctx context.Context, topic, groupID string, size int) {
+	if m == nil {
+		return
+	}
+	attrs := metric.WithAttributes(consumerAttrs(topic, groupID)...)
+	m.messages.Add(ctx, 1, attrs)
+	m.bytes.Add(ctx, int64(size), attrs)
+}
+
+func (m *consumerMetrics) failed(ctx context.Context, topic, groupID, errorType string) {
+	if m == nil {
+		return
+	}
+	m.errors.Add(ctx, 1, metric.WithAttributes(append(consumerAttrs(topic, groupID), attribute.String("error.type", errorType))...))
+}
+
+func (m *consumerMetrics) close() error {
+	if m == nil {
+		return nil
+	}
+	return m.registration.Unregister()
+}
+
+// consumerAttrs - атрибуты по OTel semconv messaging.*
+func consumerAttrs(topic, groupID string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		semconv.MessagingSystem("kafka"),
+		semconv.MessagingDestinationName(topic),
+		semconv.MessagingKafkaConsumerGroup(groupID),
+	}
+}
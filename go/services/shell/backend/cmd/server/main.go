@@ -2,19 +2,36 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"net/http"
 	"os"
-	"os/signal"
 	"path/filepath"
-	"syscall"
 	"time"
 
+	"shell/pkg/app"
 	"shell/pkg/config"
+	"shell/pkg/health"
 	"shell/pkg/logger"
 	"shell/pkg/telemetry"
 )
 
 func main() {
+	printConfig := flag.Bool("print-config", false, "print effective config with value sources and exit")
+	printSchema := flag.Bool("config-schema", false, "print JSON Schema of yaml/toml config files and exit")
+	printReference := flag.Bool("config-reference", false, "print markdown reference of config keys and exit")
+	flag.Parse()
+
+	// Схема и справочник не зависят от окружения: печатаем до загрузки конфигурации
+	if *printSchema {
+		_ = config.WriteSchema(os.Stdout)
+		return
+	}
+	if *printReference {
+		_ = config.WriteReference(os.Stdout)
+		return
+	}
+
 	// 1. Config (Prefix: SHELL)
 	loader := config.NewLoader("SHELL")
 	if err := loader.Load(); err != nil {
@@ -22,16 +39,22 @@ func main() {
 		// Но так как logger.Error использует глобальную переменную Log, которая может быть nil,
 		// лучше сначала инициализировать логгер дефолтными значениями или использовать fmt/log.
 		// В данном случае logger.Log по умолчанию инициализирован (обычно), но для надежности:
-		logger.Init("shell-bootstrap", "info")
-		logger.Error(context.Background(), "Failed to load config", "error", err)
-		os.Exit(1)
+		logger.Init("shell-bootstrap", logger.Config{Level: "info"})
+		app.New(0).Fail(fmt.Errorf("failed to load config: %w", err))
 	}
 
 	var cfg config.AppConfig
 	if err := loader.Unmarshal(&cfg); err != nil {
-		logger.Init("shell-bootstrap", "info")
-		logger.Error(context.Background(), "Failed to unmarshal config", "error", err)
-		os.Exit(1)
+		if *printConfig {
+			// Показываем конфиг и при ошибке валидации: видно, откуда пришло невалидное значение
+			_ = loader.PrintConfig(os.Stdout, &cfg)
+		}
+		logger.Init("shell-bootstrap", logger.Config{Level: "info"})
+		app.New(0).Fail(fmt.Errorf("failed to unmarshal config: %w", err))
+	}
+	if *printConfig {
+		_ = loader.PrintConfig(os.Stdout, &cfg)
+		return
 	}
 
 	// 2. Logger
@@ -41,24 +64,34 @@ func main() {
 	}
 
 	// ИСПРАВЛЕНИЕ: Удален аргумент OtelEndpoint и вызов Shutdown
-	logger.Init(serviceName, "info")
+	logger.Init(serviceName, logger.Config(cfg.Log))
 
-	// 3. Telemetry
-	shutdownTracer, err := telemetry.InitTracer(
-		context.Background(),
-		serviceName,
-		cfg.Telemetry.OtelEndpoint,
-	)
+	// 3. Telemetry: трейсы, метрики, логи в OTLP, профилирование (секция telemetry)
+	telemetryCfg := telemetry.Config(cfg.Telemetry)
+	telemetryCfg.ServiceName = serviceName
+	shutdownTelemetry, err := telemetry.Setup(context.Background(), telemetryCfg)
 	if err != nil {
-		logger.Error(context.Background(), "Failed to init tracer", "error", err)
+		logger.Error(context.Background(), "⚠️ Telemetry partially initialized", "error", err)
 	}
-	defer func() { _ = shutdownTracer(context.Background()) }()
 
-	_ = telemetry.InitProfiler(serviceName, cfg.Telemetry.PyroscopeEndpoint)
+	// Жизненный цикл: компоненты останавливаются в обратном порядке, телеметрия - последней
+	runner := app.New(cfg.Server.ShutdownTimeout)
+	runner.Add(app.Component{Name: "telemetry", Stop: shutdownTelemetry})
 
-	metricsHandler, err := telemetry.InitMetrics(serviceName)
-	if err != nil {
-		logger.Error(context.Background(), "Failed to init metrics", "error", err)
+	// Hot reload: log.level и профилирование (Pyroscope, profile_types) меняются без рестарта пода
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	if err := loader.Watch(watchCtx, func(old, new config.AppConfig) {
+		if old.Log.Level != new.Log.Level {
+			logger.SetLevel(new.Log.Level)
+		}
+		profilerCfg := telemetry.Config(new.Telemetry)
+		profilerCfg.ServiceName = serviceName
+		if err := telemetry.StartProfiler(profilerCfg); err != nil {
+			logger.Error(context.Background(), "Failed to reload profiler", "error", err)
+		}
+	}); err != nil {
+		logger.Warn(context.Background(), "⚠️ Config hot reload disabled", "error", err)
 	}
 
 	// --- FIX: Robust Static Dir Resolution ---
@@ -73,19 +106,28 @@ func main() {
 		"static_dir", resolvedStaticDir,
 	)
 
+	// Проверки состояния: /livez и /readyz (пробы k8s, health check Envoy)
+	probes := health.New(cfg.Server.HealthTimeout, cfg.Server.HealthCacheTTL, cfg.Server.ShutdownDrain)
+	probes.Add("static", health.File(filepath.Join(resolvedStaticDir, "index.html")))
+	if endpoint := telemetryCfg.CollectorEndpoint(); endpoint != "" {
+		probes.Add("otel-collector", health.Dial(endpoint), health.Optional())
+	}
+
 	// 4. HTTP Server (Static Files + API)
 	mux := http.NewServeMux()
 
-	// Health check
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(`{"status":"healthy","service":"shell"}`))
-	})
+	mux.Handle("/livez", probes.LiveHandler())
+	mux.Handle("/readyz", probes.ReadyHandler())
+	mux.Handle("/health", probes.ReadyHandler()) // Прежний путь проверок
 
-	if metricsHandler != nil {
-		mux.Handle("/metrics", metricsHandler)
-	}
+	mux.Handle("/metrics", telemetry.MetricsHandler())
+
+	// Итоговая конфигурация с источниками значений (секреты замаскированы) и уровень логов,
+	// только с Bearer server.admin_token
+	admin := http.NewServeMux()
+	admin.Handle("/admin/config", loader.Handler())
+	admin.Handle("/admin/log/level", logger.LevelHandler())
+	mux.Handle("/admin/", telemetry.RequireToken(cfg.Server.AdminToken, "admin", admin))
 
 	// Static Files
 	fs := http.FileServer(http.Dir(resolvedStaticDir))
@@ -93,32 +135,15 @@ func main() {
 
 	httpServer := &http.Server{
 		Addr:              ":" + cfg.Server.HTTPPort,
-		Handler:           mux,
+		Handler:           telemetry.ProfileHTTPMiddleware(mux, logger.HTTPMiddleware(mux)),
 		ReadHeaderTimeout: 5 * time.Second,
 	}
+	runner.Add(app.HTTP(httpServer.Addr, httpServer))
+	// Последним: при остановке первым снимает готовность, пока сервер еще обслуживает запросы
+	runner.Add(app.Component{Name: "health", Run: probes.Run, Stop: probes.Shutdown})
 
-	serverErrChan := make(chan error, 1)
-
-	go func() {
-		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			serverErrChan <- err
-		}
-	}()
-
-	// 5. Shutdown
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-
-	select {
-	case <-quit:
-		logger.Info(context.Background(), "Shutdown signal received")
-	case err := <-serverErrChan:
-		logger.Error(context.Background(), "HTTP server failed", "error", err)
-	}
-
-	if err := httpServer.Shutdown(context.Background()); err != nil {
-		logger.Error(context.Background(), "HTTP server shutdown error", "error", err)
-	}
+	// 5. Запуск до SIGTERM или первой ошибки сервера; при ошибке - выход с кодом 1
+	runner.Main()
 }
 
 func resolveStaticDir(configPath string) string {